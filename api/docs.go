@@ -31,12 +31,17 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/historical/{provider}/{date}": {
+        "/admin/audit": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get historical currency rates",
+                "summary": "List the latest changes of cached rates made via Admin API",
                 "parameters": [
                     {
                         "enum": [
@@ -46,33 +51,106 @@ var doc = `{
                         "type": "string",
                         "description": "Provider",
                         "name": "provider",
-                        "in": "path"
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AuditApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
                     },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/cache/{provider}": {
+            "delete": {
+                "security": [
                     {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Entries are selected by date range and currency pair, latest rates are purged only without date range.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Purge L1 cache entries of provider",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
                         "type": "string",
-                        "description": "Rates date (format YYYY-MM-DD)",
-                        "name": "date",
+                        "description": "Provider",
+                        "name": "provider",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "The first date (format YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The last date (format YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Base currency",
                         "name": "base",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Quoted currencies, comme separated",
-                        "name": "symbols",
-                        "in": "query",
-                        "required": true
+                        "description": "Quoted currency",
+                        "name": "symbol",
+                        "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "Force do not use any cache",
-                        "name": "force",
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type",
+                        "name": "rate_type",
                         "in": "query"
                     }
                 ],
@@ -80,18 +158,48 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.SuccessApiResponse"
+                            "$ref": "#/definitions/model.CorrectionApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
                         }
                     }
                 }
             }
         },
-        "/latest/{provider}": {
+        "/admin/gaps/{provider}": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Publication days without stored rates or with fewer stored pairs than supported.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get latest currency rates",
+                "summary": "List gaps in stored historical rates",
                 "parameters": [
                     {
                         "enum": [
@@ -101,97 +209,2315 @@ var doc = `{
                         "type": "string",
                         "description": "Provider",
                         "name": "provider",
-                        "in": "path"
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Base currency",
-                        "name": "base",
-                        "in": "query",
-                        "required": true
+                        "description": "The first scanned date (format YYYY-MM-DD), historical_start_date by default",
+                        "name": "start_date",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Quoted currencies, comme separated",
-                        "name": "symbols",
-                        "in": "query",
+                        "description": "The last scanned date (format YYYY-MM-DD), the latest published date by default",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GapsApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/gaps/{provider}/repair": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gaps are listed in response and re-fetched from provider by preload job.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Re-fetch gaps in stored historical rates",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "boolean",
-                        "description": "Force do not use any cache (except emirates-latest combination)",
-                        "name": "force",
+                        "type": "string",
+                        "description": "The first scanned date (format YYYY-MM-DD), historical_start_date by default",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The last scanned date (format YYYY-MM-DD), the latest published date by default",
+                        "name": "end_date",
                         "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/model.SuccessApiResponse"
+                            "$ref": "#/definitions/model.GapsApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
                         }
                     }
                 }
             }
         },
-        "/status": {
+        "/admin/jobs": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Using for microservice health-check by Docker",
+                "summary": "List the latest preload jobs",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.PingApiResponse"
+                            "$ref": "#/definitions/model.JobsApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "model.PingApiResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "description": "Message is service response as string",
-                    "type": "string"
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Publication days of range are fetched from provider and saved to L2 cache in background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Start preload job of provider's historical rates for date range",
+                "parameters": [
+                    {
+                        "description": "Preload job",
+                        "name": "job",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.JobApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
                 }
             }
         },
-        "model.SuccessApiResponse": {
-            "type": "object",
-            "properties": {
-                "base": {
-                    "description": "Base the three-letter currency code of the base currency used for this request.",
-                    "type": "string"
-                },
-                "date": {
-                    "description": "Date date for which historical rates were requested.",
-                    "type": "string"
-                },
-                "historical": {
-                    "description": "Historical true if a request for historical exchange rates was made.",
-                    "type": "boolean"
-                },
-                "rates": {
-                    "description": "Rates exchange rate data for the currencies you have requested.",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "number"
+        "/admin/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get preload job with state of every date",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.JobApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Failed dates are fetched from provider again in background, job becomes pending.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retry failed dates of finished preload job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.JobApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/{provider}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List days of provider with rates quarantined by ingestion-time sanity checks",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.QuarantineApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/{provider}/{date}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rates are fetched from provider and checked again on the next preload or request.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Discard quarantined rates of provider for date",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (format YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CorrectionApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/{provider}/{date}/release": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reviewed rates are saved to L2 cache as provider published them and purged from L1 cache.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Release quarantined rates of provider for date",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (format YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CorrectionApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rates/{provider}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rows are deleted from L2 cache and purged from L1 cache.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete stored historical rates of provider for date range",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The first date (format YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The last date (format YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quoted currency",
+                        "name": "symbol",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type",
+                        "name": "rate_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CorrectionApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rates/{provider}/{date}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Passed rates replace stored ones in L2 cache and are purged from L1 cache. Sanity checks are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Overwrite stored historical rates of provider for date",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (format YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Corrected rates",
+                        "name": "rates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CorrectRatesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CorrectionApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rates/{provider}/{date}/refetch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rates changed by provider are revised, prior versions are kept in revisions.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Re-fetch historical rates of provider for date",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (format YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CorrectionApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get usage counters of API access keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month (format YYYY-MM), current month by default",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UsageApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/average/{provider}/{period}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Arithmetic mean, min and max of stored historical rates over month, quarter or year.\nStatistics of closed periods are stored in L2 cache.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get average currency rates over accounting period",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period (format YYYY-MM, YYYY-Qn or YYYY)",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quoted currencies, comme separated",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type (side), mid by default",
+                        "name": "rate_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Use only rates of provider's publication days (without weekends and bank holidays)",
+                        "name": "publication_days",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "number",
+                            "string"
+                        ],
+                        "type": "string",
+                        "description": "Format of rates in response, number by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AverageApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/historical/{provider}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rates date is resolved by provider's location and publication time: before rates_generated_time\nit is the previous publication day, after it - the instant's date. If latest rates snapshots are enabled,\nrates of real-time provider actually served at the instant are returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get historical currency rates valid at given instant",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer",
+                            "composite",
+                            "consensus"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Instant (format RFC 3339, 2021-08-02T14:30:00Z)",
+                        "name": "at",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quoted currencies, comme separated",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type (side), mid by default",
+                        "name": "rate_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "number",
+                            "string"
+                        ],
+                        "type": "string",
+                        "description": "Format of rates in response, number by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Force do not use any cache",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of cached response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessApiResponse"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Cache lifetime"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Response entity tag"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Provider generated time of rates"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/historical/{provider}/{date}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get historical currency rates",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer",
+                            "composite",
+                            "consensus"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rates date (format YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quoted currencies, comme separated",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type (side), mid by default",
+                        "name": "rate_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "number",
+                            "string"
+                        ],
+                        "type": "string",
+                        "description": "Format of rates in response, number by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Force do not use any cache",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "previous"
+                        ],
+                        "type": "string",
+                        "description": "Resolve non-publication date (weekend, bank holiday) to the previous publication day",
+                        "name": "resolve",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Serve stored rates as they were known at instant (RFC 3339), before later corrections",
+                        "name": "known_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of cached response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessApiResponse"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Cache lifetime"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Response entity tag"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Provider generated time of rates"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/latest/{provider}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get latest currency rates",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer",
+                            "composite",
+                            "consensus"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quoted currencies, comme separated",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type (side), mid by default",
+                        "name": "rate_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "number",
+                            "string"
+                        ],
+                        "type": "string",
+                        "description": "Format of rates in response, number by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Force do not use any cache (except end-of-day providers latest rates)",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of cached response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessApiResponse"
+                        },
+                        "headers": {
+                            "Age": {
+                                "type": "integer",
+                                "description": "Age of stale rates in seconds"
+                            },
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Cache lifetime"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Response entity tag"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Provider generated time of rates"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshot/{provider}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get latest currency rates, served at given instant",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer",
+                            "composite",
+                            "consensus"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quoted currencies, comme separated",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Instant (format RFC 3339, 2021-08-02T14:30:00Z)",
+                        "name": "at",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type (side), mid by default",
+                        "name": "rate_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "number",
+                            "string"
+                        ],
+                        "type": "string",
+                        "description": "Format of rates in response, number by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Using for microservice health-check by Docker",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PingApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/{provider}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The first \"rates\" event contains all subscribed rates, the next ones - only rates changed since\nthe previous event, or all rates if their date is changed. Rates are polled once per provider,\nbase currency, symbols and rate type through cache, so polling is shared by all subscribers.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Stream changes of latest currency rates (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "enum": [
+                            "emirates",
+                            "fixer",
+                            "composite",
+                            "consensus"
+                        ],
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quoted currencies, comme separated",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "mid",
+                            "bid",
+                            "ask"
+                        ],
+                        "type": "string",
+                        "description": "Rate type (side), mid by default",
+                        "name": "rate_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "number",
+                            "string"
+                        ],
+                        "type": "string",
+                        "description": "Format of rates in response, number by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.FailedApiResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.ApiError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Error code (HTTP status)",
+                    "type": "integer"
+                },
+                "info": {
+                    "description": "Error message",
+                    "type": "string"
+                },
+                "retryable": {
+                    "description": "Can request be retried later (provider or database outage)",
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "Stable machine-readable error code",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AuditApiResponse": {
+            "type": "object",
+            "properties": {
+                "records": {
+                    "description": "Records the latest audit log records.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AuditRecord"
+                    }
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.AuditRecord": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action the performed action.",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "Actor the name of API access key owner, who made the change.",
+                    "type": "string"
+                },
+                "affected": {
+                    "description": "Affected the number of affected cache entries or pairs.",
+                    "type": "integer"
+                },
+                "created_time": {
+                    "description": "CreatedTime the action time (UNIX time stamp).",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Error the error message, if action failed.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID the record id.",
+                    "type": "integer"
+                },
+                "params": {
+                    "description": "Params the action parameters.",
+                    "type": "object"
+                },
+                "provider": {
+                    "description": "Provider the code of provider.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status the change status: pending (in progress or interrupted), done or failed.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AverageApiResponse": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "Base the three-letter currency code of the base currency used for this request.",
+                    "type": "string"
+                },
+                "closed": {
+                    "description": "Closed true if period is over and its averages will not change.",
+                    "type": "boolean"
+                },
+                "end_date": {
+                    "description": "EndDate the last date of period.",
+                    "type": "string"
+                },
+                "period": {
+                    "description": "Period the requested period: YYYY-MM, YYYY-Qn or YYYY.",
+                    "type": "string"
+                },
+                "publication_days": {
+                    "description": "PublicationDays true if only rates of provider's publication days were used.",
+                    "type": "boolean"
+                },
+                "rate_type": {
+                    "description": "RateType the side of rates: mid, bid or ask.",
+                    "type": "string"
+                },
+                "rates": {
+                    "description": "Rates statistics of rates over period for the currencies you have requested.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.AverageRate"
+                    }
+                },
+                "start_date": {
+                    "description": "StartDate the first date of period.",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.AverageRate": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count the number of observations (stored daily rates) used.",
+                    "type": "integer"
+                },
+                "max": {
+                    "description": "Max the maximal rate.",
+                    "type": "number"
+                },
+                "mean": {
+                    "description": "Mean arithmetic mean of rates.",
+                    "type": "number"
+                },
+                "min": {
+                    "description": "Min the minimal rate.",
+                    "type": "number"
+                }
+            }
+        },
+        "model.CorrectRatesRequest": {
+            "type": "object",
+            "required": [
+                "base",
+                "rates"
+            ],
+            "properties": {
+                "base": {
+                    "description": "Base the base currency.",
+                    "type": "string"
+                },
+                "rate_type": {
+                    "description": "RateType the rate type: mid (default), bid or ask.",
+                    "type": "string"
+                },
+                "rates": {
+                    "description": "Rates the corrected rates of quoted currencies.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "model.CorrectionApiResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action the performed action: purge_cache, delete_rates, overwrite_rates, refetch_rates, release_quarantine\nor discard_quarantine.",
+                    "type": "string"
+                },
+                "affected": {
+                    "description": "Affected the number of affected cache entries or pairs.",
+                    "type": "integer"
+                },
+                "provider": {
+                    "description": "Provider the code of provider.",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.CreateJobRequest": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "provider",
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "description": "EndDate the last date of range (format YYYY-MM-DD).",
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Provider the code of provider.",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "StartDate the first date of range (format YYYY-MM-DD).",
+                    "type": "string"
+                }
+            }
+        },
+        "model.FailedApiResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Code and error message",
+                    "$ref": "#/definitions/model.ApiError"
+                },
+                "success": {
+                    "description": "Success Returns true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.GapInfo": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Date publication day (format YYYY-MM-DD).",
+                    "type": "string"
+                },
+                "expected": {
+                    "description": "Expected the number of expected pairs, 0 if unknown.",
+                    "type": "integer"
+                },
+                "stored": {
+                    "description": "Stored the number of stored pairs.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.GapsApiResponse": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "EndDate the last scanned date.",
+                    "type": "string"
+                },
+                "gaps": {
+                    "description": "Gaps the days with missing or partially stored rates.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GapInfo"
+                    }
+                },
+                "job_id": {
+                    "description": "JobID the id of preload job started to repair gaps, 0 if repair is not started.",
+                    "type": "integer"
+                },
+                "provider": {
+                    "description": "Provider the code of provider.",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "StartDate the first scanned date.",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.JobApiResponse": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "description": "Job the preload job.",
+                    "$ref": "#/definitions/model.JobInfo"
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.JobDateInfo": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts the number of fetch attempts.",
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "Date to preload (format YYYY-MM-DD).",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error the last fetch error.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status the date status: pending, done or failed.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.JobInfo": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "description": "CreatedTime the job creation time (UNIX time stamp).",
+                    "type": "integer"
+                },
+                "dates": {
+                    "description": "Dates the state of every date of job.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.JobDateInfo"
+                    }
+                },
+                "end_date": {
+                    "description": "EndDate the last date of range.",
+                    "type": "string"
+                },
+                "finished_time": {
+                    "description": "FinishedTime the job finish time (UNIX time stamp), 0 if not finished.",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID the job id.",
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "Progress the number of dates per status.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "provider": {
+                    "description": "Provider the code of provider.",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "StartDate the first date of range.",
+                    "type": "string"
+                },
+                "started_time": {
+                    "description": "StartedTime the job start time (UNIX time stamp), 0 if not started.",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status the job status: pending, running, completed or failed.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.JobsApiResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "description": "Jobs the latest preload jobs.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.JobInfo"
+                    }
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.KeyUsage": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name the name of key owner.",
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "Requests the number of requests in month.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PingApiResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "Message is service response as string",
+                    "type": "string"
+                }
+            }
+        },
+        "model.QuarantineApiResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Days the quarantined days in ascending order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.QuarantinedDay"
+                    }
+                },
+                "provider": {
+                    "description": "Provider the code of provider.",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.QuarantinedDay": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "description": "CreatedTime the quarantine time (UNIX time stamp).",
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "Date the date of quarantined rates.",
+                    "type": "string"
+                },
+                "pairs": {
+                    "description": "Pairs the number of quarantined currency pairs.",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Reason the anomalies found in rates of the day.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.SuccessApiResponse": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "Age the age of stale rates in seconds.",
+                    "type": "integer"
+                },
+                "base": {
+                    "description": "Base the three-letter currency code of the base currency used for this request.",
+                    "type": "string"
+                },
+                "date": {
+                    "description": "Date date for which historical rates were requested.",
+                    "type": "string"
+                },
+                "effective_date": {
+                    "description": "EffectiveDate date of provider's publication the rates actually belong to.",
+                    "type": "string"
+                },
+                "historical": {
+                    "description": "Historical true if a request for historical exchange rates was made.",
+                    "type": "boolean"
+                },
+                "provider": {
+                    "description": "Provider the code of underlying provider served the rates (for aggregating providers only).",
+                    "type": "string"
+                },
+                "rate_type": {
+                    "description": "RateType the side of rates: mid, bid or ask.",
+                    "type": "string"
+                },
+                "rates": {
+                    "description": "Rates exchange rate data for the currencies you have requested.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "sources": {
+                    "description": "Sources the codes of providers contributed to consensus rate of every currency.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "stale": {
+                    "description": "Stale true if the rates are last-known-good ones, served because actual rates are not available yet.",
+                    "type": "boolean"
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
                 },
-                "success": {
-                    "description": "Success true or false depending on whether or not your API request has succeeded.",
-                    "type": "boolean"
-                },
                 "timestamp": {
                     "description": "Timestamp the exact date and time (UNIX time stamp) the given rates were collected.",
                     "type": "integer"
                 }
             }
+        },
+        "model.UsageApiResponse": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "description": "Month the month of usage (format YYYY-MM).",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Success true or false depending on whether or not your API request has succeeded.",
+                    "type": "boolean"
+                },
+                "usage": {
+                    "description": "Usage the usage counters of key owners.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.KeyUsage"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-Api-Key",
+            "in": "header"
         }
     }
 }`
@@ -211,7 +2537,7 @@ var SwaggerInfo = swaggerInfo{
 	Host:        "",
 	BasePath:    "/api/v1",
 	Schemes:     []string{},
-	Title:       "Go-forex-rates HTTP REST API server",
+	Title:       "Go-forex-rates HTTP REST API server for currency exchange rates",
 	Description: "Microservice for obtaining exchange rates",
 }
 
@@ -26,18 +26,231 @@ CREATE TABLE `currency_rate` (
   `id` int NOT NULL AUTO_INCREMENT,
   `base_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Base currency',
   `quoted_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Quoted currency',
-  `value` double NOT NULL,
+  `value` decimal(30,12) NOT NULL,
+  `rate_type` enum('mid','bid','ask') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'mid',
   `rate_date` date NOT NULL,
   `request_time` datetime NOT NULL,
   `provider_generated_time` datetime NOT NULL,
-  `provider` enum('fixer','emirates') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'fixer',
+  `provider` varchar(32) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'fixer',
   `endpoint` enum('historical','latest') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'historical',
   PRIMARY KEY (`id`),
-  UNIQUE KEY `uniq_currency_rate_quoted_base_date_endpoint` (`quoted_currency`,`base_currency`,`rate_date`,`provider`,`endpoint`)
+  UNIQUE KEY `uniq_currency_rate_quoted_base_date_endpoint` (`quoted_currency`,`base_currency`,`rate_date`,`provider`,`endpoint`,`rate_type`)
 ) ENGINE=InnoDB AUTO_INCREMENT=292583 DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 CREATE INDEX currency_rate_endpoint_provider_index ON currency_rate (endpoint, provider);
 CREATE INDEX currency_rate_endpoint_provider_rate_date_index ON currency_rate (endpoint, provider, rate_date);
+
+--
+-- Table structure for table `currency_rate_snapshot`
+--
+
+DROP TABLE IF EXISTS `currency_rate_snapshot`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `currency_rate_snapshot` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `provider` varchar(32) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `base_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Base currency',
+  `quoted_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Quoted currency',
+  `value` decimal(30,12) NOT NULL,
+  `rate_type` enum('mid','bid','ask') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'mid',
+  `rate_date` date NOT NULL,
+  `provider_generated_time` datetime NOT NULL,
+  `fetched_time` datetime(3) NOT NULL COMMENT 'Time since the rate was served',
+  PRIMARY KEY (`id`),
+  KEY `currency_rate_snapshot_pair_fetched_time_index` (`provider`,`base_currency`,`quoted_currency`,`rate_type`,`fetched_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `currency_rate_average`
+--
+
+DROP TABLE IF EXISTS `currency_rate_average`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `currency_rate_average` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `provider` varchar(32) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `base_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Base currency',
+  `quoted_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Quoted currency',
+  `rate_type` enum('mid','bid','ask') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'mid',
+  `period` varchar(7) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'YYYY-MM, YYYY-Qn or YYYY',
+  `publication_days` tinyint(1) NOT NULL DEFAULT '0',
+  `mean` decimal(30,12) NOT NULL,
+  `min` decimal(30,12) NOT NULL,
+  `max` decimal(30,12) NOT NULL,
+  `count` int NOT NULL,
+  `calculated_time` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_currency_rate_average_pair_period` (`provider`,`base_currency`,`quoted_currency`,`rate_type`,`period`,`publication_days`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `currency_rate_quarantine`
+--
+
+DROP TABLE IF EXISTS `currency_rate_quarantine`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `currency_rate_quarantine` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `provider` varchar(32) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `base_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Base currency',
+  `quoted_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Quoted currency',
+  `value` decimal(30,12) NOT NULL,
+  `rate_type` enum('mid','bid','ask') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'mid',
+  `rate_date` date NOT NULL,
+  `provider_generated_time` datetime NOT NULL,
+  `reason` text CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Anomalies found in rates of the day',
+  `created_time` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_currency_rate_quarantine_pair_date` (`provider`,`base_currency`,`quoted_currency`,`rate_type`,`rate_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `preload_job`
+--
+
+DROP TABLE IF EXISTS `preload_job`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `preload_job` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `provider` varchar(32) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `start_date` date NOT NULL COMMENT 'The first date of range',
+  `end_date` date NOT NULL COMMENT 'The last date of range',
+  `status` enum('pending','running','completed','failed') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'pending',
+  `created_time` datetime NOT NULL,
+  `started_time` datetime DEFAULT NULL,
+  `finished_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `preload_job_provider_status_index` (`provider`,`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `preload_job_date`
+--
+
+DROP TABLE IF EXISTS `preload_job_date`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `preload_job_date` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `job_id` int NOT NULL,
+  `rate_date` date NOT NULL,
+  `status` enum('pending','done','failed') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'pending',
+  `attempts` int NOT NULL DEFAULT '0',
+  `error` text CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'The last fetch error',
+  `updated_time` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_preload_job_date_job_date` (`job_id`,`rate_date`),
+  CONSTRAINT `preload_job_date_job_fk` FOREIGN KEY (`job_id`) REFERENCES `preload_job` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `lease`
+--
+
+DROP TABLE IF EXISTS `lease`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `lease` (
+  `name` varchar(64) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `holder` varchar(255) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT '' COMMENT 'Id of instance holding the lease',
+  `expires_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `api_key`
+--
+
+DROP TABLE IF EXISTS `api_key`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `api_key` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Unique name of key owner',
+  `access_key` varchar(128) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `rate_limit` int NOT NULL DEFAULT '0' COMMENT 'Requests per minute, 0 - unlimited',
+  `monthly_quota` int NOT NULL DEFAULT '0' COMMENT 'Requests per month, 0 - unlimited',
+  `providers` varchar(255) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT '' COMMENT 'Comma separated allowed providers, empty - all',
+  `admin` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'Allow access to Admin API',
+  `enabled` tinyint(1) NOT NULL DEFAULT '1',
+  `created_time` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_api_key_name` (`name`),
+  UNIQUE KEY `uniq_api_key_access_key` (`access_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `api_key_usage`
+--
+
+DROP TABLE IF EXISTS `api_key_usage`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `api_key_usage` (
+  `name` varchar(64) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Name of key owner',
+  `month` char(7) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'YYYY-MM',
+  `requests` int NOT NULL DEFAULT '0',
+  `updated_time` datetime NOT NULL,
+  PRIMARY KEY (`name`,`month`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `audit_log`
+--
+
+DROP TABLE IF EXISTS `audit_log`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `audit_log` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `actor` varchar(64) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Name of API access key owner',
+  `action` enum('purge_cache','delete_rates','overwrite_rates','refetch_rates','release_quarantine','discard_quarantine') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `provider` varchar(32) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Provider code',
+  `params` text CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Action parameters (JSON)',
+  `status` enum('pending','done','failed') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'done' COMMENT 'Pending record is saved before the change',
+  `affected` int NOT NULL DEFAULT '0',
+  `error` text CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `created_time` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `audit_log_provider_index` (`provider`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `currency_rate_revision`
+--
+
+DROP TABLE IF EXISTS `currency_rate_revision`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `currency_rate_revision` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `provider` varchar(32) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL,
+  `base_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Base currency',
+  `quoted_currency` char(3) CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL COMMENT 'Quoted currency',
+  `value` decimal(30,12) NOT NULL,
+  `rate_type` enum('mid','bid','ask') CHARACTER SET utf8 COLLATE utf8_unicode_ci NOT NULL DEFAULT 'mid',
+  `rate_date` date NOT NULL,
+  `provider_generated_time` datetime NOT NULL,
+  `known_from_time` datetime NOT NULL COMMENT 'Time since the rate was known',
+  `known_until_time` datetime NOT NULL COMMENT 'Time since the rate was replaced by corrected one',
+  PRIMARY KEY (`id`),
+  KEY `currency_rate_revision_pair_date_index` (`provider`,`base_currency`,`quoted_currency`,`rate_type`,`rate_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Dumping data for table `currency_rate`
 --
@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/PuerkitoBio/goquery v1.5.1
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751
+	github.com/andybalholm/cascadia v1.2.0 // indirect
 	github.com/eko/gocache v1.2.0
 	github.com/fatih/color v1.12.0
 	github.com/gin-gonic/gin v1.7.2
@@ -13,7 +14,6 @@ require (
 	github.com/go-openapi/spec v0.20.3 // indirect
 	github.com/go-openapi/swag v0.19.15 // indirect
 	github.com/go-playground/validator/v10 v10.6.1 // indirect
-	github.com/gocolly/colly/v2 v2.1.0
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/ilyakaznacheev/cleanenv v1.2.5
 	github.com/json-iterator/go v1.1.11 // indirect
@@ -21,6 +21,7 @@ require (
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/mattn/go-isatty v0.0.13 // indirect
 	github.com/patrickmn/go-cache v2.1.0+incompatible
+	github.com/shopspring/decimal v1.3.1
 	github.com/swaggo/files v0.0.0-20190704085106-630677cd5c14
 	github.com/swaggo/gin-swagger v1.3.0
 	github.com/swaggo/swag v1.7.0
@@ -28,8 +29,11 @@ require (
 	go.uber.org/dig v1.11.0
 	golang.org/x/crypto v0.0.0-20210616213533-5ff15b29337e // indirect
 	golang.org/x/net v0.0.0-20210805182204-aaa1db679c0d // indirect
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 	golang.org/x/sys v0.0.0-20210809222454-d867a43fc93e // indirect
 	golang.org/x/tools v0.1.5 // indirect
+	google.golang.org/grpc v1.38.0
+	google.golang.org/protobuf v1.26.0
 	gorm.io/driver/mysql v1.1.1
 	gorm.io/gorm v1.21.11
 )
@@ -0,0 +1,219 @@
+// Package anomaly implements ingestion-time sanity checks of provider's rates
+package anomaly
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultReciprocalTolerance is used if reciprocal tolerance is not configured
+const DefaultReciprocalTolerance = 0.0001
+
+// previousRatesWindow is number of days before checked date to search previous rates in
+const previousRatesWindow = 10
+
+// Rates is one day rates of provider, which should be checked before saving
+type Rates struct {
+	// Provider code
+	Provider string
+
+	// Base currency of direct rates
+	BaseCurrency string
+
+	// Rate type: mid, bid or ask
+	RateType string
+
+	// The date of rates
+	Date time.Time
+
+	// Provider generated rates time
+	ProviderGeneratedTime time.Time
+
+	// Direct rates: quoted currency per one base currency
+	Direct map[string]decimal.Decimal
+
+	// Reverse rates: base currency per one quoted currency (optional)
+	Reverse map[string]decimal.Decimal
+
+	// Quoted currencies, expected in direct rates (optional)
+	Expected []string
+
+	// Number of decimal places, which derived rates are rounded to (util.DefaultPrecision if not set)
+	Precision int32
+}
+
+// Detector checks fetched rates and quarantines suspicious ones instead of saving
+type Detector struct {
+	config     *model.ApplicationConfig
+	rates      *repository.RateRepository
+	quarantine *repository.QuarantineRepository
+}
+
+// BuildDetector /* *Detector
+func BuildDetector(
+	config *model.ApplicationConfig,
+	rates *repository.RateRepository,
+	quarantine *repository.QuarantineRepository) (*Detector, error) {
+	return NewDetector(config, rates, quarantine), nil
+}
+
+// NewDetector constructor
+func NewDetector(
+	config *model.ApplicationConfig,
+	rates *repository.RateRepository,
+	quarantine *repository.QuarantineRepository) *Detector {
+	return &Detector{
+		config:     config,
+		rates:      rates,
+		quarantine: quarantine,
+	}
+}
+
+// Approve checks rates, quarantines them and logs alert if anomalies found. Rates of already quarantined day
+// are not quarantined and alerted again. Returns found anomalies, empty if rates can be saved
+func (d *Detector) Approve(rates Rates) []string {
+	anomalies := d.Check(rates)
+	if len(anomalies) == 0 {
+		return anomalies
+	}
+	quarantined, err := d.quarantine.Exists(rates.Provider, rates.BaseCurrency, rates.RateType, rates.Date)
+	if err != nil {
+		logger.LogWarning("Quarantined rates are not checked. "+err.Error(), "ANOMALY")
+	}
+	if quarantined {
+		return anomalies
+	}
+	logger.LogError("Rates of provider "+rates.Provider+" for "+rates.Date.Format(util.DateFormatEu)+
+		" are quarantined: "+strings.Join(anomalies, "; "), "ANOMALY")
+	if err := d.quarantine.Save(d.buildEntities(rates, anomalies)); err != nil {
+		logger.LogError("Quarantined rates are not saved. "+err.Error(), "ANOMALY")
+	}
+	return anomalies
+}
+
+// Check returns anomalies found in rates: missing currencies, inconsistent reciprocal rates
+// and day-over-day jumps over threshold
+func (d *Detector) Check(rates Rates) []string {
+	var anomalies []string
+	config := d.config.Providers[rates.Provider].Anomaly
+
+	// Missing currencies
+	if config.MaxMissingCurrencies > 0 && len(rates.Expected) > 0 {
+		var missing []string
+		for _, currency := range rates.Expected {
+			if _, ok := rates.Direct[currency]; !ok && currency != rates.BaseCurrency {
+				missing = append(missing, currency)
+			}
+		}
+		if len(missing) > config.MaxMissingCurrencies {
+			anomalies = append(anomalies, strconv.Itoa(len(missing))+" currencies are missing: "+strings.Join(missing, ","))
+		}
+	}
+
+	// Reciprocal consistency. One of rates is derived from another and rounded, so rounding error of both rates
+	// (half of the last decimal place relative to the rate) is allowed in addition to configured tolerance
+	tolerance := decimal.NewFromFloat(config.ReciprocalTolerance)
+	if config.ReciprocalTolerance <= 0 {
+		tolerance = decimal.NewFromFloat(DefaultReciprocalTolerance)
+	}
+	precision := rates.Precision
+	if precision <= 0 {
+		precision = util.DefaultPrecision
+	}
+	halfUnit := decimal.New(5, -precision-1)
+	one := decimal.NewFromInt(1)
+	for _, currency := range d.getSortedCurrencies(rates.Reverse) {
+		direct, ok := rates.Direct[currency]
+		reverse := rates.Reverse[currency]
+		if !ok || direct.IsZero() || reverse.IsZero() {
+			continue
+		}
+		allowed := tolerance.Add(halfUnit.Div(direct)).Add(halfUnit.Div(reverse))
+		if direct.Mul(reverse).Sub(one).Abs().GreaterThan(allowed) {
+			anomalies = append(anomalies, "direct rate "+direct.String()+" and reverse rate "+
+				reverse.String()+" of "+currency+" are inconsistent")
+		}
+	}
+
+	// Day-over-day jumps
+	if config.MaxDailyChange <= 0 && len(config.MaxDailyChanges) == 0 {
+		return anomalies
+	}
+	currencies := d.getSortedCurrencies(rates.Direct)
+	previous, err := d.rates.FindPrevious(rates.Provider, rates.BaseCurrency, currencies, rates.RateType, rates.Date, previousRatesWindow)
+	if err != nil {
+		logger.LogWarning("Day-over-day check is skipped. "+err.Error(), "ANOMALY")
+		return anomalies
+	}
+
+	// Rates already stored for the date were approved before (released from quarantine, corrected), re-fetched
+	// unchanged ones are not checked again
+	stored, err := d.rates.FindPrevious(rates.Provider, rates.BaseCurrency, currencies, rates.RateType, rates.Date.AddDate(0, 0, 1), 1)
+	if err != nil {
+		logger.LogWarning("Day-over-day check is skipped. "+err.Error(), "ANOMALY")
+		return anomalies
+	}
+	for _, currency := range currencies {
+		if storedRate, ok := stored[currency]; ok && storedRate.Equal(rates.Direct[currency]) {
+			continue
+		}
+		maxChange, ok := config.MaxDailyChanges[currency]
+		if !ok {
+			maxChange = config.MaxDailyChange
+		}
+		previousRate, ok := previous[currency]
+		if maxChange <= 0 || !ok || previousRate.IsZero() {
+			continue
+		}
+		change := rates.Direct[currency].Sub(previousRate).Div(previousRate).Abs()
+		if change.GreaterThan(decimal.NewFromFloat(maxChange)) {
+			anomalies = append(anomalies, "rate of "+currency+" changed from "+previousRate.String()+" to "+
+				rates.Direct[currency].String()+" ("+change.Mul(decimal.NewFromInt(100)).StringFixed(2)+"%)")
+		}
+	}
+	return anomalies
+}
+
+// buildEntities builds quarantine entities of all rates of the day
+func (d *Detector) buildEntities(rates Rates, anomalies []string) []*entity.QuarantinedRate {
+	var entities []*entity.QuarantinedRate
+	reason := strings.Join(anomalies, "; ")
+	build := func(baseCurrency string, quotedCurrency string, value decimal.Decimal) *entity.QuarantinedRate {
+		return &entity.QuarantinedRate{
+			Provider:              rates.Provider,
+			BaseCurrency:          baseCurrency,
+			QuotedCurrency:        quotedCurrency,
+			Value:                 value,
+			RateType:              rates.RateType,
+			RateDate:              rates.Date.Format(util.DateFormatEu),
+			ProviderGeneratedTime: rates.ProviderGeneratedTime.UTC(),
+			Reason:                reason,
+			CreatedTime:           time.Now().UTC(),
+		}
+	}
+	for currency, rate := range rates.Direct {
+		entities = append(entities, build(rates.BaseCurrency, currency, rate))
+	}
+	for currency, rate := range rates.Reverse {
+		entities = append(entities, build(currency, rates.BaseCurrency, rate))
+	}
+	return entities
+}
+
+// getSortedCurrencies returns currencies of rates in alphabetical order, to report anomalies in stable order
+func (d *Detector) getSortedCurrencies(rates map[string]decimal.Decimal) []string {
+	var currencies []string
+	for currency := range rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
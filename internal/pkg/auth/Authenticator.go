@@ -0,0 +1,344 @@
+// Package auth implements API access keys validation, rate limits and monthly quotas
+package auth
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryParam is query parameter with API access key
+const QueryParam = "access_key"
+
+// queryParamPattern matches API access key in query string of request URI
+var queryParamPattern = regexp.MustCompile(`([?&])` + QueryParam + `=[^&]*`)
+
+// ContextKey is gin context key of authenticated key owner name
+const ContextKey = "api_key_name"
+
+// MonthFormat is format of usage counters month
+const MonthFormat = "2006-01"
+
+// keyCacheTTL is lifetime of database keys loaded to memory, and of unknown keys remembered as missing
+const keyCacheTTL = time.Minute
+
+// flushInterval is interval of usage counters saving to database
+const flushInterval = 10 * time.Second
+
+// cachedKey is database key loaded to memory, key is nil if it is not found in database
+type cachedKey struct {
+	key      *model.ApiKeyConfig
+	loadTime time.Time
+}
+
+// window is number of key owner's requests in current minute
+type window struct {
+	start    time.Time
+	requests int
+}
+
+// Authenticator validates API access keys from config and database, checks allowed providers, per-minute rate limits
+// and monthly quotas. Rate limits are counted by every instance, usage counters are saved to database
+// and shared by all instances
+type Authenticator struct {
+	config *model.ApplicationConfig
+	keys   *repository.ApiKeyRepository
+	clock  util.Clock
+
+	mu        sync.Mutex
+	cache     map[string]cachedKey
+	windows   map[string]*window
+	month     string
+	persisted map[string]int
+	pending   map[string]int
+}
+
+// BuildAuthenticator /* *Authenticator
+func BuildAuthenticator(
+	config *model.ApplicationConfig,
+	keys *repository.ApiKeyRepository,
+	clock util.Clock) (*Authenticator, error) {
+	return NewAuthenticator(config, keys, clock), nil
+}
+
+// NewAuthenticator constructor
+func NewAuthenticator(config *model.ApplicationConfig, keys *repository.ApiKeyRepository, clock util.Clock) *Authenticator {
+	return &Authenticator{
+		config:    config,
+		keys:      keys,
+		clock:     clock,
+		cache:     make(map[string]cachedKey),
+		windows:   make(map[string]*window),
+		persisted: make(map[string]int),
+		pending:   make(map[string]int),
+	}
+}
+
+// Start loads usage counters of current month and saves them to database in background
+func (a *Authenticator) Start() {
+	if !a.config.Auth.Enabled {
+		return
+	}
+	a.Flush()
+	go func() {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			a.Flush()
+		}
+	}()
+}
+
+// Handler is gin middleware, which rejects requests without valid API access key
+func (a *Authenticator) Handler() gin.HandlerFunc {
+	return a.handler(false)
+}
+
+// AdminHandler is gin middleware, which rejects requests without valid API access key allowed to access Admin API
+func (a *Authenticator) AdminHandler() gin.HandlerFunc {
+	return a.handler(true)
+}
+
+// handler builds gin middleware, which authenticates requests
+func (a *Authenticator) handler(admin bool) gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		if !a.config.Auth.Enabled {
+			c.Next()
+			return
+		}
+		accessKey := c.GetHeader(a.config.Auth.Header)
+		if accessKey == "" {
+			accessKey = c.Query(QueryParam)
+		}
+		name, err := a.authenticate(accessKey, c.Param("provider"), admin, 1)
+		if err != nil {
+			response := model.NewFailedApiResponseFromError(err)
+			c.AbortWithStatusJSON(response.Error.Code, response)
+			return
+		}
+		c.Set(ContextKey, name)
+		c.Next()
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// Authenticate validates access key and counts request. Returns name of key owner
+func (a *Authenticator) Authenticate(accessKey string, providerCode string) (string, error) {
+	return a.authenticate(accessKey, providerCode, false, 1)
+}
+
+// AuthenticateBatch validates access key and counts request serving rates of several dates as passed number
+// of requests against monthly quota, rate limit counts it once. Returns name of key owner
+func (a *Authenticator) AuthenticateBatch(accessKey string, providerCode string, requests int) (string, error) {
+	if requests < 1 {
+		requests = 1
+	}
+	return a.authenticate(accessKey, providerCode, false, requests)
+}
+
+// authenticate validates access key, its access to Admin API if admin is true, and counts requests
+func (a *Authenticator) authenticate(accessKey string, providerCode string, admin bool, requests int) (string, error) {
+	if accessKey == "" {
+		return "", customerror.NewUnauthorizedError("access key is required. Pass it in " +
+			a.config.Auth.Header + " header or " + QueryParam + " query parameter")
+	}
+	key, err := a.findKey(accessKey)
+	if err != nil {
+		return "", err
+	}
+	if key == nil {
+		return "", customerror.NewUnauthorizedError("access key is invalid")
+	}
+	if admin && !key.Admin {
+		return "", customerror.NewForbiddenError("Admin API is not allowed for access key")
+	}
+	if providerCode != "" && len(key.Providers) > 0 && !util.Contains(key.Providers, providerCode) {
+		return "", customerror.NewForbiddenError("provider " + providerCode + " is not allowed for access key")
+	}
+
+	// Counters of previous month are saved to database after lock is released
+	now := a.clock.Now().UTC()
+	a.mu.Lock()
+	month, pending := a.switchMonth(now.Format(MonthFormat))
+	err = a.count(key, now, requests)
+	a.mu.Unlock()
+	a.saveUsage(month, pending)
+	if err != nil {
+		return "", err
+	}
+	return key.Name, nil
+}
+
+// count checks rate limit and monthly quota of key and counts requests. Should be called under lock
+func (a *Authenticator) count(key *model.ApiKeyConfig, now time.Time, requests int) error {
+	// Rate limit
+	current, ok := a.windows[key.Name]
+	if !ok || now.Sub(current.start) >= time.Minute {
+		current = &window{start: now.Truncate(time.Minute)}
+		a.windows[key.Name] = current
+	}
+	if key.RateLimit > 0 && current.requests >= key.RateLimit {
+		return customerror.NewRateLimitError("rate limit of access key is reached. Try again in a minute")
+	}
+
+	// Monthly quota
+	if key.MonthlyQuota > 0 && a.persisted[key.Name]+a.pending[key.Name]+requests > key.MonthlyQuota {
+		return customerror.NewQuotaError("monthly quota of access key is reached")
+	}
+	current.requests++
+	a.pending[key.Name] += requests
+	return nil
+}
+
+// RedactAccessKey replaces API access key in query string of request URI, so it is not written to logs
+func RedactAccessKey(uri string) string {
+	return queryParamPattern.ReplaceAllString(uri, "${1}"+QueryParam+"=***")
+}
+
+// GetActor returns name of authenticated key owner of request, "anonymous" if auth is disabled
+func GetActor(c *gin.Context) string {
+	if name := c.GetString(ContextKey); name != "" {
+		return name
+	}
+	return "anonymous"
+}
+
+// GetUsage saves usage counters and returns usage of all key owners in month (format YYYY-MM)
+func (a *Authenticator) GetUsage(month string) ([]entity.ApiKeyUsage, error) {
+	a.Flush()
+	return a.keys.FindUsage(month)
+}
+
+// Flush saves pending usage counters to database and reloads counters of all instances
+func (a *Authenticator) Flush() {
+	if !a.config.Auth.Enabled {
+		return
+	}
+	a.mu.Lock()
+	month := a.month
+	if month == "" {
+		month = a.clock.Now().UTC().Format(MonthFormat)
+		a.month = month
+	}
+	pending := a.pending
+	a.pending = make(map[string]int)
+	a.mu.Unlock()
+
+	failed := make(map[string]int)
+	for name, requests := range pending {
+		if err := a.keys.AddUsage(name, month, requests); err != nil {
+			logger.LogError("Usage of access key "+name+" is not saved. "+err.Error(), "AUTH")
+			failed[name] = requests
+		}
+	}
+	usage, err := a.keys.FindUsage(month)
+	if err != nil {
+		logger.LogError("Usage of access keys is not loaded. "+err.Error(), "AUTH")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pruneKeys()
+	if a.month != month {
+		return
+	}
+	for name, requests := range failed {
+		a.pending[name] += requests
+	}
+	if err == nil {
+		a.persisted = make(map[string]int)
+		for _, u := range usage {
+			a.persisted[u.Name] = u.Requests
+		}
+	}
+}
+
+// pruneKeys removes expired keys loaded to memory. Should be called under lock
+func (a *Authenticator) pruneKeys() {
+	now := a.clock.Now()
+	for accessKey, cached := range a.cache {
+		if now.Sub(cached.loadTime) >= keyCacheTTL {
+			delete(a.cache, accessKey)
+		}
+	}
+}
+
+// switchMonth resets usage counters at the beginning of month. Returns previous month and its pending counters,
+// which should be saved by caller. Should be called under lock
+func (a *Authenticator) switchMonth(month string) (string, map[string]int) {
+	if a.month == month {
+		return "", nil
+	}
+	previousMonth, pending := a.month, a.pending
+	a.month = month
+	a.persisted = make(map[string]int)
+	a.pending = make(map[string]int)
+	return previousMonth, pending
+}
+
+// saveUsage adds pending usage counters of month to database. Should be called without lock
+func (a *Authenticator) saveUsage(month string, pending map[string]int) {
+	if month == "" {
+		return
+	}
+	for name, requests := range pending {
+		if err := a.keys.AddUsage(name, month, requests); err != nil {
+			logger.LogError("Usage of access key "+name+" is not saved. "+err.Error(), "AUTH")
+		}
+	}
+}
+
+// findKey returns key from config or database, nil if key is not found. Configured keys are compared
+// in constant time, so the key can not be guessed by response timing
+func (a *Authenticator) findKey(accessKey string) (*model.ApiKeyConfig, error) {
+	for i, key := range a.config.Auth.Keys {
+		if subtle.ConstantTimeCompare([]byte(key.Key), []byte(accessKey)) == 1 {
+			return &a.config.Auth.Keys[i], nil
+		}
+	}
+
+	now := a.clock.Now()
+	a.mu.Lock()
+	cached, ok := a.cache[accessKey]
+	a.mu.Unlock()
+	if ok && now.Sub(cached.loadTime) < keyCacheTTL {
+		return cached.key, nil
+	}
+
+	e, err := a.keys.FindByAccessKey(accessKey)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		// Remember missing key, so requests with wrong key do not query database every time
+		a.mu.Lock()
+		a.cache[accessKey] = cachedKey{loadTime: now}
+		a.mu.Unlock()
+		return nil, nil
+	}
+	key := &model.ApiKeyConfig{
+		Name:         e.Name,
+		Key:          e.AccessKey,
+		RateLimit:    e.RateLimit,
+		MonthlyQuota: e.MonthlyQuota,
+		Admin:        e.Admin,
+	}
+	for _, code := range strings.Split(e.Providers, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			key.Providers = append(key.Providers, code)
+		}
+	}
+	a.mu.Lock()
+	a.cache[accessKey] = cachedKey{key: key, loadTime: now}
+	a.mu.Unlock()
+	return key, nil
+}
@@ -0,0 +1,125 @@
+// Package snapshot keeps last-known-good latest rates of providers
+package snapshot
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+// rateSnapshot is last-known-good rate of single currency pair
+type rateSnapshot struct {
+	rate      decimal.Decimal
+	timestamp int64
+	provider  string
+	sources   []string
+	date      time.Time
+	fetchedAt time.Time
+}
+
+// LatestSnapshots is in-memory storage of last-known-good latest rates per provider, base currency and rate type
+type LatestSnapshots struct {
+	mu        sync.RWMutex
+	clock     util.Clock
+	snapshots map[string]map[string]rateSnapshot
+}
+
+// BuildLatestSnapshots /* *LatestSnapshots
+func BuildLatestSnapshots(clock util.Clock) (*LatestSnapshots, error) {
+	return NewLatestSnapshots(clock), nil
+}
+
+// NewLatestSnapshots constructor
+func NewLatestSnapshots(clock util.Clock) *LatestSnapshots {
+	return &LatestSnapshots{
+		clock:     clock,
+		snapshots: make(map[string]map[string]rateSnapshot),
+	}
+}
+
+// Save remembers rates, successfully fetched from provider for passed request
+func (s *LatestSnapshots) Save(serviceRequest model.RatesRequest, serviceResponse model.RatesResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.getKey(serviceRequest)
+	if s.snapshots[key] == nil {
+		s.snapshots[key] = make(map[string]rateSnapshot)
+	}
+	fetchedAt := s.clock.Now()
+	for currency, rate := range serviceResponse.Rates {
+		s.snapshots[key][currency] = rateSnapshot{
+			rate:      rate,
+			timestamp: serviceResponse.Timestamp,
+			provider:  serviceResponse.Provider,
+			sources:   serviceResponse.Sources[currency],
+			date:      serviceRequest.Date,
+			fetchedAt: fetchedAt,
+		}
+	}
+}
+
+// Get returns last-known-good rates for passed request, date they belong to and their age.
+// Returns false if any of requested currencies has no snapshot or it is older than maxAge
+func (s *LatestSnapshots) Get(serviceRequest model.RatesRequest, maxAge time.Duration) (model.RatesResponse, time.Time, time.Duration, bool) {
+	var (
+		date            time.Time
+		fetchedAt       time.Time
+		providers       = make(map[string]bool)
+		serviceResponse = model.RatesResponse{
+			Rates:   make(map[string]decimal.Decimal),
+			Sources: make(map[string][]string),
+		}
+	)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := s.snapshots[s.getKey(serviceRequest)]
+	if len(serviceRequest.Symbols) == 0 {
+		return serviceResponse, date, 0, false
+	}
+	for _, currency := range serviceRequest.Symbols {
+		snapshot, ok := snapshots[currency]
+		if !ok {
+			return serviceResponse, date, 0, false
+		}
+		serviceResponse.Rates[currency] = snapshot.rate
+		if len(snapshot.sources) > 0 {
+			serviceResponse.Sources[currency] = snapshot.sources
+		}
+		providers[snapshot.provider] = true
+		if snapshot.timestamp > serviceResponse.Timestamp {
+			serviceResponse.Timestamp = snapshot.timestamp
+		}
+
+		// The oldest pair defines age and date of the whole snapshot
+		if fetchedAt.IsZero() || snapshot.fetchedAt.Before(fetchedAt) {
+			fetchedAt = snapshot.fetchedAt
+		}
+		if date.IsZero() || snapshot.date.Before(date) {
+			date = snapshot.date
+		}
+	}
+	age := s.clock.Now().Sub(fetchedAt)
+	if age > maxAge {
+		return serviceResponse, date, age, false
+	}
+
+	// Underlying provider is known only if all pairs were served by the same one
+	if len(providers) == 1 {
+		for provider := range providers {
+			serviceResponse.Provider = provider
+		}
+	}
+	if len(serviceResponse.Sources) == 0 {
+		serviceResponse.Sources = nil
+	}
+	return serviceResponse, date, age, true
+}
+
+// getKey returns snapshot key of passed request
+func (s *LatestSnapshots) getKey(serviceRequest model.RatesRequest) string {
+	return serviceRequest.ProviderCode + "|" + serviceRequest.BaseCurrency + "|" + serviceRequest.GetRateType()
+}
@@ -4,12 +4,16 @@ package cachestore
 import (
 	"encoding/json"
 	"github.com/eko/gocache/store"
+	"github.com/netandreus/go-forex-rates/internal/pkg/anomaly"
 	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
 	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
 	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
+	"strings"
 	"time"
 )
 
@@ -18,19 +22,30 @@ const MySQLType = "mysql"
 
 // MySQLStore used for store immutable (historical) currency rates in L2 cache in database (MySQL)
 type MySQLStore struct {
-	client  *gorm.DB
-	options *store.Options
+	client   *gorm.DB
+	detector *anomaly.Detector
+	rates    *repository.RateRepository
+	clock    util.Clock
+	options  *store.Options
 }
 
 // NewMySQLStore creates a new store to Memcache instance(s)
-func NewMySQLStore(client *gorm.DB, options *store.Options) *MySQLStore {
+func NewMySQLStore(
+	client *gorm.DB,
+	detector *anomaly.Detector,
+	rates *repository.RateRepository,
+	clock util.Clock,
+	options *store.Options) *MySQLStore {
 	if options == nil {
 		options = &store.Options{}
 	}
 
 	return &MySQLStore{
-		client:  client,
-		options: options,
+		client:   client,
+		detector: detector,
+		rates:    rates,
+		clock:    clock,
+		options:  options,
 	}
 }
 
@@ -80,36 +95,38 @@ func (store *MySQLStore) Set(key interface{}, value interface{}, options *store.
 	if err != nil {
 		return err
 	}
-	if store.canSet(serviceRequest) {
-		store.saveByKey(serviceRequest, *serviceResponse)
+	if store.canSet(serviceRequest, *serviceResponse) {
+		return store.saveByKey(serviceRequest, *serviceResponse)
 	}
 	return nil
 }
 
 // canSet detect ability to store value in L2 cache
-func (store *MySQLStore) canSet(serviceRequest model.RatesRequest) bool {
-	var (
-		location *time.Location
-		today    time.Time
-	)
-
+func (store *MySQLStore) canSet(serviceRequest model.RatesRequest, serviceResponse model.RatesResponse) bool {
 	if serviceRequest.Endpoint == util.EndpointLatest {
 		return false
 	}
-	location, _ = time.LoadLocation(serviceRequest.ProviderLocationName)
-	today = util.GetToday(location)
-	if util.IsDateEquals(serviceRequest.Date, today) || serviceRequest.Date.After(today) {
+
+	// Rates served by underlying providers of aggregating one are not stored under aggregating provider code
+	if serviceResponse.Provider != "" && serviceResponse.Provider != serviceRequest.ProviderCode {
+		return false
+	}
+	if len(serviceResponse.Sources) > 0 {
 		return false
 	}
 
-	return true
+	// Today's rates can be changed by provider till the end of the day
+	location, _ := time.LoadLocation(serviceRequest.ProviderLocationName)
+	now := store.clock.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return serviceRequest.Date.Before(today)
 }
 
 // loadByKey uses internally for fetching from database
 func (store *MySQLStore) loadByKey(serviceRequest model.RatesRequest) (string, error) {
 	var (
 		providerGeneratedTime time.Time
-		rates                 = make(map[string]float64)
+		rates                 = make(map[string]decimal.Decimal)
 		ratesResponse         = &model.RatesResponse{}
 		entities              []entity.CurrencyRate
 		symbols               []string
@@ -134,6 +151,7 @@ func (store *MySQLStore) loadByKey(serviceRequest model.RatesRequest) (string, e
 		Where("quoted_currency IN (?)", serviceRequest.Symbols).
 		Where("endpoint = ?", util.EndpointHistorical).
 		Where("provider = ?", serviceRequest.ProviderCode).
+		Where("rate_type = ?", serviceRequest.GetRateType()).
 		Where("rate_date = ?", serviceRequest.Date.Format(util.DateFormatEu)).
 		Find(&entities)
 	if result.Error != nil {
@@ -153,7 +171,7 @@ func (store *MySQLStore) loadByKey(serviceRequest model.RatesRequest) (string, e
 
 	// Add base currency back to symbols
 	if len(symbols) != len(serviceRequest.Symbols) {
-		rates[serviceRequest.BaseCurrency] = 1
+		rates[serviceRequest.BaseCurrency] = decimal.NewFromInt(1)
 	}
 	ratesResponse = &model.RatesResponse{
 		Rates:     rates,
@@ -165,6 +183,29 @@ func (store *MySQLStore) loadByKey(serviceRequest model.RatesRequest) (string, e
 
 // saveByKey uses internally for save to database
 func (store *MySQLStore) saveByKey(key model.RatesRequest, value model.RatesResponse) error {
+	// Sanity checks, suspicious rates are quarantined instead of saving
+	directRates := make(map[string]decimal.Decimal)
+	for quotedCurrency, rate := range value.Rates {
+		if quotedCurrency != key.BaseCurrency {
+			directRates[quotedCurrency] = rate
+		}
+	}
+	anomalies := store.detector.Approve(anomaly.Rates{
+		Provider:              key.ProviderCode,
+		BaseCurrency:          key.BaseCurrency,
+		RateType:              key.GetRateType(),
+		Date:                  key.Date,
+		ProviderGeneratedTime: time.Unix(value.Timestamp, 0),
+		Direct:                directRates,
+		Expected:              key.Symbols,
+	})
+	if len(anomalies) > 0 {
+		return customerror.NewUnprocessableError("rates of provider " + key.ProviderCode + " for " +
+			key.Date.Format(util.DateFormatEu) + " are quarantined pending review. " + strings.Join(anomalies, "; "))
+	}
+
+	// Already stored rates, which were changed by provider, are revised
+	var revised []string
 	for quotedCurrency, rate := range value.Rates {
 		if key.BaseCurrency == quotedCurrency {
 			continue
@@ -176,11 +217,21 @@ func (store *MySQLStore) saveByKey(key model.RatesRequest, value model.RatesResp
 			RateDate:              key.Date.Format(util.DateFormatEu),
 			ProviderGeneratedTime: time.Unix(value.Timestamp, 0),
 			RequestTime:           time.Now().UTC(),
-			Value:                 util.ToFixed(rate, 6),
+			Value:                 rate,
+			RateType:              key.GetRateType(),
 			Provider:              key.ProviderCode,
 		}
-		// OnConflict is need for On duplicate key cause.
-		store.client.Clauses(clause.OnConflict{DoNothing: true}).Create(entity)
+		isRevised, err := store.rates.Save(entity)
+		if err != nil {
+			return err
+		}
+		if isRevised {
+			revised = append(revised, key.BaseCurrency+quotedCurrency)
+		}
+	}
+	if len(revised) > 0 {
+		logger.LogWarning("Rates of provider "+key.ProviderCode+" for "+key.Date.Format(util.DateFormatEu)+
+			" are revised: "+strings.Join(revised, ", "), "REVISION")
 	}
 	return nil
 }
@@ -190,17 +241,104 @@ func (store *MySQLStore) GetType() string {
 	return MySQLType
 }
 
-// Delete does not affected, as MySQLStore is persistent storage
+// Delete deletes stored historical rates of cache key
 func (store *MySQLStore) Delete(key interface{}) error {
+	var serviceRequest = model.RatesRequest{}
+	if err := json.Unmarshal([]byte(key.(string)), &serviceRequest); err != nil {
+		return err
+	}
+	if serviceRequest.Endpoint != util.EndpointHistorical {
+		return nil
+	}
+	for _, symbol := range serviceRequest.Symbols {
+		if symbol == serviceRequest.BaseCurrency {
+			continue
+		}
+		_, err := store.DeleteRates(model.RatesFilter{
+			ProviderCode:   serviceRequest.ProviderCode,
+			StartDate:      serviceRequest.Date,
+			EndDate:        serviceRequest.Date,
+			BaseCurrency:   serviceRequest.BaseCurrency,
+			QuotedCurrency: symbol,
+			RateType:       serviceRequest.GetRateType(),
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Invalidate does not affected, as MySQLStore is persistent storage
+// Invalidate deletes stored historical rates selected by tags: "provider:<code>" (required), "date:<YYYY-MM-DD>",
+// "base:<currency>", "symbol:<currency>", "rate_type:<type>"
 func (store *MySQLStore) Invalidate(options store.InvalidateOptions) error {
-	return nil
+	var filter model.RatesFilter
+	for _, tag := range options.TagsValue() {
+		parts := strings.SplitN(tag, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "provider":
+			filter.ProviderCode = parts[1]
+		case "date":
+			date, err := time.ParseInLocation(util.DateFormatEu, parts[1], time.UTC)
+			if err != nil {
+				return err
+			}
+			filter.StartDate, filter.EndDate = date, date
+		case "base":
+			filter.BaseCurrency = parts[1]
+		case "symbol":
+			filter.QuotedCurrency = parts[1]
+		case "rate_type":
+			filter.RateType = parts[1]
+		}
+	}
+	if filter.ProviderCode == "" {
+		return nil
+	}
+	_, err := store.DeleteRates(filter)
+	return err
 }
 
-// Clear does not affected, as MySQLStore is persistent storage
+// Clear does not affected, as MySQLStore is persistent storage. Use Invalidate to delete selected rates
 func (store *MySQLStore) Clear() error {
 	return nil
 }
+
+// DeleteRates deletes stored historical rates selected by filter, prior values are kept in revisions.
+// Returns number of deleted pairs
+func (store *MySQLStore) DeleteRates(filter model.RatesFilter) (int64, error) {
+	if filter.ProviderCode == "" {
+		return 0, customerror.NewBadRequestError("provider is required to delete rates")
+	}
+	return store.rates.Delete(filter, time.Now().UTC())
+}
+
+// Overwrite saves historical rates of cache key replacing stored values, prior values are kept in revisions.
+// Sanity checks are skipped, as it is used for manual correction. Returns number of saved pairs
+func (store *MySQLStore) Overwrite(key model.RatesRequest, value model.RatesResponse) (int64, error) {
+	var saved int64
+	for quotedCurrency, rate := range value.Rates {
+		if key.BaseCurrency == quotedCurrency {
+			continue
+		}
+		e := &entity.CurrencyRate{
+			Endpoint:              util.EndpointHistorical,
+			BaseCurrency:          key.BaseCurrency,
+			QuotedCurrency:        quotedCurrency,
+			RateDate:              key.Date.Format(util.DateFormatEu),
+			ProviderGeneratedTime: time.Unix(value.Timestamp, 0),
+			RequestTime:           time.Now().UTC(),
+			Value:                 rate,
+			RateType:              key.GetRateType(),
+			Provider:              key.ProviderCode,
+		}
+		if _, err := store.rates.Save(e); err != nil {
+			return saved, err
+		}
+		saved++
+	}
+	return saved, nil
+}
@@ -0,0 +1,434 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/auth"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/preload"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// jobsListLimit is max number of jobs in list
+const jobsListLimit = 100
+
+// AdminController is controller of maintenance API
+type AdminController struct {
+	config     *model.ApplicationConfig
+	registry   *provider.Registry
+	gapScanner *preload.GapScanner
+	jobManager *preload.JobManager
+	jobs       *repository.JobRepository
+	auth       *auth.Authenticator
+	clock      util.Clock
+}
+
+// NewAdminController is the constructor
+func NewAdminController(
+	config *model.ApplicationConfig,
+	registry *provider.Registry,
+	gapScanner *preload.GapScanner,
+	jobManager *preload.JobManager,
+	jobs *repository.JobRepository,
+	auth *auth.Authenticator,
+	clock util.Clock) *AdminController {
+	return &AdminController{
+		config:     config,
+		registry:   registry,
+		gapScanner: gapScanner,
+		jobManager: jobManager,
+		jobs:       jobs,
+		auth:       auth,
+		clock:      clock,
+	}
+}
+
+// Gaps godoc
+// @Summary List gaps in stored historical rates
+// @Description Publication days without stored rates or with fewer stored pairs than supported.
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param start_date query string false "The first scanned date (format YYYY-MM-DD), historical_start_date by default"
+// @Param end_date query string false "The last scanned date (format YYYY-MM-DD), the latest published date by default"
+// @Success 200 {object} model.GapsApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/gaps/{provider} [get]
+func (controller *AdminController) Gaps() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		controller.handleGaps(c, false)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// RepairGaps godoc
+// @Summary Re-fetch gaps in stored historical rates
+// @Description Gaps are listed in response and re-fetched from provider by preload job.
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param start_date query string false "The first scanned date (format YYYY-MM-DD), historical_start_date by default"
+// @Param end_date query string false "The last scanned date (format YYYY-MM-DD), the latest published date by default"
+// @Success 202 {object} model.GapsApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/gaps/{provider}/repair [post]
+func (controller *AdminController) RepairGaps() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		controller.handleGaps(c, true)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// handleGaps scans provider's history for gaps and starts their repair if needed
+func (controller *AdminController) handleGaps(c *gin.Context, repair bool) {
+	var (
+		err        error
+		prov       provider.RatesProvider
+		start, end time.Time
+		gaps       []preload.Gap
+		unfinished bool
+		status     = http.StatusOK
+	)
+
+	// Init provider
+	if prov, err = controller.registry.GetProvider(c.Param("provider")); err != nil {
+		controller.respondError(c, err)
+		return
+	}
+	if repair {
+		if err = controller.checkPreload(prov); err != nil {
+			controller.respondError(c, err)
+			return
+		}
+
+		// Unfinished job of provider will continue preload, repair jobs should not overlap
+		if unfinished, err = controller.jobManager.HasUnfinished(prov.GetCode()); err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		if unfinished {
+			controller.respondError(c, customerror.NewUnprocessableError("provider "+prov.GetCode()+" has unfinished preload job"))
+			return
+		}
+	}
+
+	// Date range
+	if start, end, err = controller.gapScanner.GetDefaultRange(prov); err != nil {
+		controller.respondError(c, customerror.NewUnprocessableError(err.Error()))
+		return
+	}
+	if start, err = controller.parseDate(c, "start_date", start); err != nil {
+		controller.respondError(c, err)
+		return
+	}
+	if end, err = controller.parseDate(c, "end_date", end); err != nil {
+		controller.respondError(c, err)
+		return
+	}
+
+	// Scan
+	if gaps, err = controller.gapScanner.Scan(prov, start, end); err != nil {
+		controller.respondError(c, err)
+		return
+	}
+	response := model.GapsApiResponse{
+		Success:   true,
+		Provider:  prov.GetCode(),
+		StartDate: start.Format(util.DateFormatEu),
+		EndDate:   end.Format(util.DateFormatEu),
+		Gaps:      make([]model.GapInfo, 0, len(gaps)),
+	}
+	for _, gap := range gaps {
+		response.Gaps = append(response.Gaps, model.GapInfo{
+			Date:     gap.Date.Format(util.DateFormatEu),
+			Stored:   gap.Stored,
+			Expected: gap.Expected,
+		})
+	}
+
+	// Repair by preload job
+	if repair && len(gaps) > 0 {
+		job, err := controller.jobManager.Start(prov, controller.gapScanner.GetDates(gaps))
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		response.JobID = job.ID
+		status = http.StatusAccepted
+	}
+	c.JSON(status, response)
+}
+
+// Jobs godoc
+// @Summary List the latest preload jobs
+// @Produce json
+// @Success 200 {object} model.JobsApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/jobs [get]
+func (controller *AdminController) Jobs() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		jobs, err := controller.jobs.FindAll(jobsListLimit)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		response := model.JobsApiResponse{
+			Success: true,
+			Jobs:    make([]model.JobInfo, 0, len(jobs)),
+		}
+		for i := range jobs {
+			info, err := controller.buildJobInfo(&jobs[i])
+			if err != nil {
+				controller.respondError(c, err)
+				return
+			}
+			response.Jobs = append(response.Jobs, info)
+		}
+		c.JSON(http.StatusOK, response)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// Job godoc
+// @Summary Get preload job with state of every date
+// @Produce json
+// @Param id path integer true "Job id"
+// @Success 200 {object} model.JobApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/jobs/{id} [get]
+func (controller *AdminController) Job() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Invalid job id. "+err.Error()))
+			return
+		}
+		job, err := controller.jobs.Find(uint(id))
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		info, err := controller.buildJobInfo(job)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		for _, date := range job.Dates {
+			info.Dates = append(info.Dates, model.JobDateInfo{
+				Date:     date.RateDate,
+				Status:   date.Status,
+				Attempts: date.Attempts,
+				Error:    date.Error,
+			})
+		}
+		c.JSON(http.StatusOK, model.JobApiResponse{Success: true, Job: info})
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// CreateJob godoc
+// @Summary Start preload job of provider's historical rates for date range
+// @Description Publication days of range are fetched from provider and saved to L2 cache in background.
+// @Accept json
+// @Produce json
+// @Param job body model.CreateJobRequest true "Preload job"
+// @Success 202 {object} model.JobApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/jobs [post]
+func (controller *AdminController) CreateJob() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		var (
+			err        error
+			request    model.CreateJobRequest
+			prov       provider.RatesProvider
+			start, end time.Time
+		)
+		if err = c.ShouldBindJSON(&request); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. "+err.Error()))
+			return
+		}
+		if prov, err = controller.registry.GetProvider(request.Provider); err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		if err = controller.checkPreload(prov); err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		if start, err = time.ParseInLocation(util.DateFormatEu, request.StartDate, time.UTC); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Invalid start_date. "+err.Error()))
+			return
+		}
+		if end, err = time.ParseInLocation(util.DateFormatEu, request.EndDate, time.UTC); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Invalid end_date. "+err.Error()))
+			return
+		}
+		job, err := controller.jobManager.StartRange(prov, start, end)
+		if err != nil {
+			if customerror.GetCode(err) == customerror.CodeInternal {
+				err = customerror.NewUnprocessableError(err.Error())
+			}
+			controller.respondError(c, err)
+			return
+		}
+		info, err := controller.buildJobInfo(job)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, model.JobApiResponse{Success: true, Job: info})
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// RetryJob godoc
+// @Summary Retry failed dates of finished preload job
+// @Description Failed dates are fetched from provider again in background, job becomes pending.
+// @Produce json
+// @Param id path integer true "Job id"
+// @Success 202 {object} model.JobApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/jobs/{id}/retry [post]
+func (controller *AdminController) RetryJob() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Invalid job id. "+err.Error()))
+			return
+		}
+		job, err := controller.jobManager.Retry(uint(id))
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		info, err := controller.buildJobInfo(job)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, model.JobApiResponse{Success: true, Job: info})
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// Usage godoc
+// @Summary Get usage counters of API access keys
+// @Produce json
+// @Param month query string false "Month (format YYYY-MM), current month by default"
+// @Success 200 {object} model.UsageApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/usage [get]
+func (controller *AdminController) Usage() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		month := c.DefaultQuery("month", controller.clock.Now().UTC().Format(auth.MonthFormat))
+		if _, err := time.Parse(auth.MonthFormat, month); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Invalid month. "+err.Error()))
+			return
+		}
+		usage, err := controller.auth.GetUsage(month)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		response := model.UsageApiResponse{
+			Success: true,
+			Month:   month,
+			Usage:   make([]model.KeyUsage, 0, len(usage)),
+		}
+		for _, u := range usage {
+			response.Usage = append(response.Usage, model.KeyUsage{Name: u.Name, Requests: u.Requests})
+		}
+		c.JSON(http.StatusOK, response)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// buildJobInfo builds job response with its progress
+func (controller *AdminController) buildJobInfo(job *entity.PreloadJob) (model.JobInfo, error) {
+	progress, err := controller.jobs.CountDates(job.ID)
+	if err != nil {
+		return model.JobInfo{}, err
+	}
+	info := model.JobInfo{
+		ID:          job.ID,
+		Provider:    job.Provider,
+		StartDate:   job.StartDate,
+		EndDate:     job.EndDate,
+		Status:      job.Status,
+		Progress:    progress,
+		CreatedTime: job.CreatedTime.Unix(),
+	}
+	if job.StartedTime != nil {
+		info.StartedTime = job.StartedTime.Unix()
+	}
+	if job.FinishedTime != nil {
+		info.FinishedTime = job.FinishedTime.Unix()
+	}
+	return info, nil
+}
+
+// checkPreload returns error if provider can not preload rates to L2 cache
+func (controller *AdminController) checkPreload(prov provider.RatesProvider) error {
+	if !prov.GetCapabilities().Preload {
+		return customerror.NewUnprocessableError("provider " + prov.GetCode() + " does not support rates preload")
+	}
+	return nil
+}
+
+// parseDate returns date from query parameter (format YYYY-MM-DD) or default one
+func (controller *AdminController) parseDate(c *gin.Context, name string, defaultDate time.Time) (time.Time, error) {
+	dateStr := c.Query(name)
+	if dateStr == "" {
+		return defaultDate, nil
+	}
+	date, err := time.ParseInLocation(util.DateFormatEu, dateStr, time.UTC)
+	if err != nil {
+		return date, customerror.NewBadRequestError("error parsing request. Invalid " + name + ". " + err.Error())
+	}
+	return date, nil
+}
+
+// respondError writes failed response with HTTP status and machine-readable code of passed typed error
+func (controller *AdminController) respondError(c *gin.Context, err error) {
+	response := model.NewFailedApiResponseFromError(err)
+	c.JSON(response.Error.Code, response)
+}
@@ -2,45 +2,58 @@
 package controller
 
 import (
-	"github.com/eko/gocache/cache"
-	"github.com/eko/gocache/store"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
 	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/pipeline"
 	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
-	"github.com/netandreus/go-forex-rates/internal/pkg/provider/emirates"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
 	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // ApiController is main API controller of application
 type ApiController struct {
-	db       *gorm.DB
-	config   *model.ApplicationConfig
-	cache    *cache.ChainCache
-	registry *provider.Registry
+	db           *gorm.DB
+	config       *model.ApplicationConfig
+	registry     *provider.Registry
+	availability *provider.Availability
+	snapshotRepo *repository.SnapshotRepository
+	averageRepo  *repository.AverageRepository
+	pipeline     *pipeline.Pipeline
+	clock        util.Clock
 }
 
 // NewApiController is the constructor
 func NewApiController(db *gorm.DB,
 	config *model.ApplicationConfig,
-	cache *cache.ChainCache,
-	registry *provider.Registry) *ApiController {
+	registry *provider.Registry,
+	availability *provider.Availability,
+	snapshotRepo *repository.SnapshotRepository,
+	averageRepo *repository.AverageRepository,
+	pipeline *pipeline.Pipeline,
+	clock util.Clock) *ApiController {
 	return &ApiController{
-		db:       db,
-		config:   config,
-		cache:    cache,
-		registry: registry,
+		db:           db,
+		config:       config,
+		registry:     registry,
+		availability: availability,
+		snapshotRepo: snapshotRepo,
+		averageRepo:  averageRepo,
+		pipeline:     pipeline,
+		clock:        clock,
 	}
 }
 
-// isDebug returns bool value is debug mode on?
-func (controller *ApiController) isDebug() bool {
-	return controller.config.Engine.Mode == gin.DebugMode
-}
-
 // Status godoc
 // @Summary Using for microservice health-check by Docker
 // @Produce json
@@ -56,28 +69,40 @@ func (controller *ApiController) Status() gin.HandlerFunc {
 // Historical godoc
 // @Summary Get historical currency rates
 // @Produce json
-// @Param provider path string false "Provider" Enums(emirates, fixer)
+// @Param provider path string false "Provider" Enums(emirates, fixer, composite, consensus)
 // @Param date path string true "Rates date (format YYYY-MM-DD)"
 // @Param base query string true "Base currency"
 // @Param symbols query string true "Quoted currencies, comme separated"
+// @Param rate_type query string false "Rate type (side), mid by default" Enums(mid, bid, ask)
+// @Param format query string false "Format of rates in response, number by default" Enums(number, string)
 // @Param force query boolean false "Force do not use any cache"
+// @Param resolve query string false "Resolve non-publication date (weekend, bank holiday) to the previous publication day" Enums(previous)
+// @Param known_at query string false "Serve stored rates as they were known at instant (RFC 3339), before later corrections"
+// @Param If-None-Match header string false "ETag of cached response"
+// @Param If-Modified-Since header string false "Last-Modified of cached response"
 // @Success 200 {object} model.SuccessApiResponse
+// @Header 200 {string} Cache-Control "Cache lifetime"
+// @Header 200 {string} ETag "Response entity tag"
+// @Header 200 {string} Last-Modified "Provider generated time of rates"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 502 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 504 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 429 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
 // @Router /historical/{provider}/{date} [get]
 func (controller *ApiController) Historical() gin.HandlerFunc {
 	fn := func(c *gin.Context) {
-		var (
-			err             error
-			prov            provider.RatesProvider
-			serviceRequest  = model.RatesRequest{}
-			serviceResponse = model.RatesResponse{
-				Timestamp: 0,
-				Rates:     make(map[string]float64),
-			}
-		)
+		var serviceRequest = model.RatesRequest{}
 
 		// Parse HTTP request params
-		if err = serviceRequest.FromGinContext(c, controller.config, util.EndpointHistorical); err != nil {
-			c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
+		if err := serviceRequest.FromGinContext(c, controller.config, util.EndpointHistorical); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError(err.Error()))
 			return
 		}
 
@@ -87,174 +112,368 @@ func (controller *ApiController) Historical() gin.HandlerFunc {
 			return
 		}
 
-		// Init provider
-		if prov, err = controller.registry.GetProvider(serviceRequest.ProviderCode); err != nil {
-			c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
+		// Get rates from cache or provider
+		serviceResponse, maxAge, err := controller.pipeline.GetHistorical(&serviceRequest)
+		if err != nil {
+			controller.respondError(c, err)
 			return
 		}
 
-		// Result for request today's historical rates
-		providerLocation := prov.GetLocation()
-		if util.IsDateEquals(serviceRequest.Date, util.GetToday(providerLocation)) {
-			serviceRequest.Date = util.GetYesterday(providerLocation)
-		}
+		// Return response
+		controller.respondCacheable(c, serviceRequest, serviceResponse, maxAge)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// HistoricalAt godoc
+// @Summary Get historical currency rates valid at given instant
+// @Description Rates date is resolved by provider's location and publication time: before rates_generated_time
+// @Description it is the previous publication day, after it - the instant's date. If latest rates snapshots are enabled,
+// @Description rates of real-time provider actually served at the instant are returned.
+// @Produce json
+// @Param provider path string false "Provider" Enums(emirates, fixer, composite, consensus)
+// @Param at query string true "Instant (format RFC 3339, 2021-08-02T14:30:00Z)"
+// @Param base query string true "Base currency"
+// @Param symbols query string true "Quoted currencies, comme separated"
+// @Param rate_type query string false "Rate type (side), mid by default" Enums(mid, bid, ask)
+// @Param format query string false "Format of rates in response, number by default" Enums(number, string)
+// @Param force query boolean false "Force do not use any cache"
+// @Param If-None-Match header string false "ETag of cached response"
+// @Param If-Modified-Since header string false "Last-Modified of cached response"
+// @Success 200 {object} model.SuccessApiResponse
+// @Header 200 {string} Cache-Control "Cache lifetime"
+// @Header 200 {string} ETag "Response entity tag"
+// @Header 200 {string} Last-Modified "Provider generated time of rates"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 502 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 504 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 429 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /historical/{provider} [get]
+func (controller *ApiController) HistoricalAt() gin.HandlerFunc {
+	return controller.Historical()
+}
+
+// Latest godoc
+// @Summary Get latest currency rates
+// @Produce json
+// @Param provider path string false "Provider" Enums(emirates, fixer, composite, consensus)
+// @Param base query string true "Base currency"
+// @Param symbols query string true "Quoted currencies, comme separated"
+// @Param rate_type query string false "Rate type (side), mid by default" Enums(mid, bid, ask)
+// @Param format query string false "Format of rates in response, number by default" Enums(number, string)
+// @Param force query boolean false "Force do not use any cache (except end-of-day providers latest rates)"
+// @Param If-None-Match header string false "ETag of cached response"
+// @Param If-Modified-Since header string false "Last-Modified of cached response"
+// @Success 200 {object} model.SuccessApiResponse
+// @Header 200 {string} Cache-Control "Cache lifetime"
+// @Header 200 {string} ETag "Response entity tag"
+// @Header 200 {string} Last-Modified "Provider generated time of rates"
+// @Header 200 {integer} Age "Age of stale rates in seconds"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 502 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 504 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 429 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /latest/{provider} [get]
+func (controller *ApiController) Latest() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		var serviceRequest = model.RatesRequest{}
 
-		// Cache get
-		cacheKey, _ := serviceRequest.String()
-		cacheValue, err := controller.cache.Get(cacheKey)
+		// Parse HTTP request params
+		if err := serviceRequest.FromGinContext(c, controller.config, util.EndpointLatest); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. "+err.Error()))
+			return
+		}
 
-		if err != nil && !strings.Contains(err.Error(), "Value not found") {
-			c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
+		// Get rates from cache or provider
+		serviceResponse, maxAge, err := controller.pipeline.GetLatest(&serviceRequest)
+		if err != nil {
+			controller.respondError(c, err)
 			return
 		}
-		if cacheValue != nil && !serviceRequest.Force {
-			if controller.isDebug() {
-				logger.LogSuccess("Found", "CACHE")
-			}
-			// Unmarshall
-			if err := serviceResponse.FromString(cacheValue.(string)); err != nil {
-				c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
-				return
-			}
-		} else {
-			if controller.isDebug() {
-				logger.LogWarning("Not found", "CACHE")
-			}
-			// Get rates
-			if controller.isDebug() {
-				logger.LogWarning("Request provider \""+serviceRequest.ProviderCode+"\" API", "API")
-			}
-			if serviceResponse, err = prov.GetHistoricalRates(serviceRequest); err != nil {
-				c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
-				return
-			}
-			// Cache set
-			if !serviceRequest.Force {
-				expiration := time.Duration(controller.config.L1Cache.DefaultExpiration) * time.Second
-
-				// Marshall
-				cacheValueStr, err := serviceResponse.String()
-				if err != nil {
-					c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
-					return
-				}
-				// Set to cache
-				controller.cache.Set(cacheKey, cacheValueStr, &store.Options{Expiration: expiration})
-				if controller.isDebug() {
-					logger.LogSuccess("Saved to cache with key: "+string(cacheKey), "CACHE")
-				}
-			}
+
+		// BaseCurrency = QuotedCurrency ?
+		if serviceRequest.IsEqualCurrencyRequest() {
+			c.JSON(200, model.NewSuccessApiResponseCurrencyEquals(serviceRequest))
+			return
 		}
 
 		// Return response
-		c.JSON(200, model.NewSuccessApiResponse(serviceRequest, serviceResponse))
+		controller.respondCacheable(c, serviceRequest, serviceResponse, maxAge)
 	}
 	return gin.HandlerFunc(fn)
 }
 
-// Latest godoc
-// @Summary Get latest currency rates
+// Snapshot godoc
+// @Summary Get latest currency rates, served at given instant
 // @Produce json
-// @Param provider path string false "Provider" Enums(emirates, fixer)
+// @Param provider path string false "Provider" Enums(emirates, fixer, composite, consensus)
 // @Param base query string true "Base currency"
 // @Param symbols query string true "Quoted currencies, comme separated"
-// @Param force query boolean false "Force do not use any cache (except emirates-latest combination)"
+// @Param at query string true "Instant (format RFC 3339, 2021-08-02T14:30:00Z)"
+// @Param rate_type query string false "Rate type (side), mid by default" Enums(mid, bid, ask)
+// @Param format query string false "Format of rates in response, number by default" Enums(number, string)
 // @Success 200 {object} model.SuccessApiResponse
-// @Router /latest/{provider} [get]
-func (controller *ApiController) Latest() gin.HandlerFunc {
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 429 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /snapshot/{provider} [get]
+func (controller *ApiController) Snapshot() gin.HandlerFunc {
 	fn := func(c *gin.Context) {
 		var (
 			err             error
 			prov            provider.RatesProvider
 			serviceRequest  = model.RatesRequest{}
-			serviceResponse = model.RatesResponse{
-				Timestamp: 0,
-				Rates:     make(map[string]float64),
-			}
-			date time.Time
-			now  = time.Now()
+			serviceResponse model.RatesResponse
 		)
 
 		// Parse HTTP request params
 		if err = serviceRequest.FromGinContext(c, controller.config, util.EndpointLatest); err != nil {
-			c.JSON(400, model.NewFailedApiResponse(400, "error parsing request. "+err.Error()))
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. "+err.Error()))
+			return
+		}
+		if serviceRequest.At.IsZero() {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Instant (at) is required"))
+			return
+		}
+		if !controller.config.Latest.Snapshots {
+			controller.respondError(c, customerror.NewUnprocessableError("latest rates snapshots are disabled"))
 			return
 		}
 
-		// Correct service request (Define correct date)
-		if serviceRequest.ProviderCode == emirates.Code {
-			prov, _ := controller.registry.GetProvider(emirates.Code)
-			rateGenerationTime := prov.GetRateGenerationTime()
-			if now.Hour() >= rateGenerationTime.Hour() && now.Minute() > rateGenerationTime.Minute() && now.Second() > rateGenerationTime.Second() {
-				date = util.GetToday(time.UTC)
-			} else {
-				date = util.GetYesterday(time.UTC)
-				serviceRequest.Endpoint = util.EndpointHistorical
-			}
-		} else {
-			// For L1 cache (in-memory) can save and load
-			// date = time.Time{}
-			date = util.GetToday(time.UTC)
+		// Init provider
+		if prov, err = controller.registry.GetProvider(serviceRequest.ProviderCode); err != nil {
+			controller.respondError(c, err)
+			return
 		}
-		serviceRequest.Date = date
 
-		// BaseCurrency = QuotedCurrency ?
-		if serviceRequest.IsEqualCurrencyRequest() {
-			c.JSON(200, model.NewSuccessApiResponseCurrencyEquals(serviceRequest))
+		// Load snapshot
+		at := serviceRequest.At.In(prov.GetLocation())
+		serviceRequest.RequestedDate = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		if serviceResponse, serviceRequest.Date, err = controller.snapshotRepo.FindAt(serviceRequest, serviceRequest.At); err != nil {
+			controller.respondError(c, err)
 			return
 		}
+		if serviceRequest.Date.IsZero() {
+			serviceRequest.Date = serviceRequest.RequestedDate
+		}
+
+		// Rates served at past instant are immutable
+		maxAge := controller.pipeline.GetLatestMaxAge()
+		if serviceRequest.At.Before(controller.clock.Now()) {
+			maxAge = pipeline.ImmutableMaxAge
+		}
+		controller.respondCacheable(c, serviceRequest, serviceResponse, maxAge)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// Average godoc
+// @Summary Get average currency rates over accounting period
+// @Description Arithmetic mean, min and max of stored historical rates over month, quarter or year.
+// @Description Statistics of closed periods are stored in L2 cache.
+// @Produce json
+// @Param provider path string false "Provider" Enums(emirates, fixer)
+// @Param period path string true "Period (format YYYY-MM, YYYY-Qn or YYYY)"
+// @Param base query string true "Base currency"
+// @Param symbols query string true "Quoted currencies, comme separated"
+// @Param rate_type query string false "Rate type (side), mid by default" Enums(mid, bid, ask)
+// @Param publication_days query boolean false "Use only rates of provider's publication days (without weekends and bank holidays)"
+// @Param format query string false "Format of rates in response, number by default" Enums(number, string)
+// @Success 200 {object} model.AverageApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 429 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /average/{provider}/{period} [get]
+func (controller *ApiController) Average() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		var (
+			err            error
+			prov           provider.RatesProvider
+			averageRequest = model.AverageRequest{}
+			rates          = make(map[string]model.AverageRate)
+			symbols        []string
+		)
 
-		// Cache get
-		cacheKey, _ := serviceRequest.String()
-		cacheValue, err := controller.cache.Get(cacheKey)
+		// Parse HTTP request params
+		if err = averageRequest.FromGinContext(c); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. "+err.Error()))
+			return
+		}
 
-		if err != nil && !strings.Contains(err.Error(), "Value not found") {
-			c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
+		// Init provider
+		if prov, err = controller.registry.GetProvider(averageRequest.ProviderCode); err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		ratesRequest := model.RatesRequest{
+			Endpoint:     util.EndpointHistorical,
+			ProviderCode: averageRequest.ProviderCode,
+			Date:         averageRequest.Period.Start,
+			BaseCurrency: averageRequest.BaseCurrency,
+			Symbols:      averageRequest.Symbols,
+			RateType:     averageRequest.RateType,
+		}
+		if _, err = prov.IsRequestValid(ratesRequest); err != nil {
+			controller.respondError(c, customerror.NewUnprocessableError("request is invalid. "+err.Error()))
 			return
 		}
 
-		if cacheValue != nil && !serviceRequest.Force {
-			if controller.isDebug() {
-				logger.LogSuccess("Found", "CACHE")
-			}
-			// Unmarshall
-			if err := serviceResponse.FromString(cacheValue.(string)); err != nil {
-				c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
+		// Statistics of closed period are stored in L2 cache till rates of the period are corrected
+		calendar := prov.GetCapabilities().Calendar
+		closed := !averageRequest.Period.End.After(controller.availability.GetLatestDate(prov))
+		if closed {
+			if rates, err = controller.averageRepo.FindStored(averageRequest); err != nil {
+				controller.respondError(c, err)
 				return
 			}
-		} else {
-			if controller.isDebug() {
-				logger.LogWarning("Not found", "CACHE")
-			}
-			if prov, err = controller.registry.GetProvider(serviceRequest.ProviderCode); err != nil {
-				c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
-				return
+		}
+		for _, symbol := range averageRequest.Symbols {
+			if _, ok := rates[symbol]; !ok && symbol != averageRequest.BaseCurrency {
+				symbols = append(symbols, symbol)
 			}
-			// Get rates
-			if serviceResponse, err = prov.GetLatestRates(serviceRequest); err != nil {
-				c.JSON(400, model.NewFailedApiResponse(400, err.Error()))
+		}
+
+		// Calculate the others from stored historical rates
+		isObservationDay := func(date time.Time) bool {
+			return !averageRequest.PublicationDays || calendar.IsPublicationDay(date)
+		}
+		calculated, err := controller.averageRepo.Calculate(averageRequest, symbols, isObservationDay, prov.GetPrecision())
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		for _, symbol := range symbols {
+			if _, ok := calculated[symbol]; !ok {
+				controller.respondError(c, customerror.NewNotFoundError("there are no stored rates "+
+					averageRequest.BaseCurrency+"/"+symbol+" for period "+averageRequest.Period.Name))
 				return
 			}
+		}
 
-			// Cache set
-			if !serviceRequest.Force {
-				expiration := time.Duration(controller.config.L1Cache.DefaultExpiration) * time.Second
-				// Marshall
-				cacheValueStr, err := serviceResponse.String()
-				if err != nil {
-					response := model.NewFailedApiResponse(400, err.Error())
-					c.JSON(400, response)
-					return
-				}
-				controller.cache.Set(cacheKey, cacheValueStr, &store.Options{Expiration: expiration})
-				if controller.isDebug() {
-					logger.LogSuccess("Set cache value with key "+cacheKey, "CACHE")
+		// Save statistics of closed period, if rates of all publication days were used
+		if closed {
+			publicationDays := len(calendar.FilterPublicationDays(
+				util.GetDateRangeArr(averageRequest.Period.Start, averageRequest.Period.End)))
+			complete := make(map[string]model.AverageRate)
+			for currency, rate := range calculated {
+				if rate.Count >= publicationDays {
+					complete[currency] = rate
 				}
 			}
+			if err = controller.averageRepo.Save(averageRequest, complete); err != nil {
+				logger.LogError("Average rates are not saved. "+err.Error(), "DB")
+			}
 		}
-
-		// Return response
-		c.JSON(200, model.NewSuccessApiResponse(serviceRequest, serviceResponse))
+		for currency, rate := range calculated {
+			rates[currency] = rate
+		}
+		if util.Contains(averageRequest.Symbols, averageRequest.BaseCurrency) {
+			one := decimal.NewFromInt(1)
+			rates[averageRequest.BaseCurrency] = model.AverageRate{Mean: one, Min: one, Max: one}
+		}
+		c.JSON(200, model.NewAverageApiResponse(averageRequest, rates, closed))
 	}
 	return gin.HandlerFunc(fn)
 }
+
+// respondError writes failed response with HTTP status and machine-readable code of passed typed error
+func (controller *ApiController) respondError(c *gin.Context, err error) {
+	response := model.NewFailedApiResponseFromError(err)
+	c.JSON(response.Error.Code, response)
+}
+
+// respondCacheable writes success response with HTTP caching headers (Cache-Control, ETag, Last-Modified)
+// or 304 Not Modified response if client already has actual copy of it
+func (controller *ApiController) respondCacheable(
+	c *gin.Context,
+	serviceRequest model.RatesRequest,
+	serviceResponse model.RatesResponse,
+	maxAge time.Duration) {
+	var lastModified time.Time
+
+	body, err := json.Marshal(model.NewSuccessApiResponse(serviceRequest, serviceResponse))
+	if err != nil {
+		controller.respondError(c, err)
+		return
+	}
+
+	// Forced request is always actual, it should not be cached
+	if serviceRequest.Force {
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+
+	// Caching headers
+	if serviceResponse.Stale {
+		c.Header("Age", strconv.FormatInt(serviceResponse.Age, 10))
+	}
+	hash := sha1.Sum(body)
+	etag := "\"" + hex.EncodeToString(hash[:]) + "\""
+	cacheControl := "public"
+	if controller.config.Auth.Enabled {
+		// Response depends on API access key, shared caches must not serve it to other clients
+		cacheControl = "private"
+		c.Header("Vary", controller.config.Auth.Header)
+	}
+	cacheControl += ", max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	if maxAge == pipeline.ImmutableMaxAge {
+		cacheControl += ", immutable"
+	}
+	c.Header("Cache-Control", cacheControl)
+	c.Header("ETag", etag)
+	if serviceResponse.Timestamp > 0 {
+		lastModified = time.Unix(serviceResponse.Timestamp, 0).UTC()
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	// Conditional request. If-Modified-Since is ignored when If-None-Match is present
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if controller.isETagMatch(ifNoneMatch, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !lastModified.After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// isETagMatch returns true if If-None-Match header value matches passed ETag
+func (controller *ApiController) isETagMatch(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
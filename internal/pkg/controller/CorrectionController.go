@@ -0,0 +1,393 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/auth"
+	"github.com/netandreus/go-forex-rates/internal/pkg/correction"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditListLimit is default number of audit log records in list
+const auditListLimit = 100
+
+// CorrectionController is controller of cached rates invalidation, correction and quarantine review Admin API
+type CorrectionController struct {
+	registry   *provider.Registry
+	corrector  *correction.Corrector
+	audit      *repository.AuditRepository
+	quarantine *repository.QuarantineRepository
+}
+
+// NewCorrectionController is the constructor
+func NewCorrectionController(
+	registry *provider.Registry,
+	corrector *correction.Corrector,
+	audit *repository.AuditRepository,
+	quarantine *repository.QuarantineRepository) *CorrectionController {
+	return &CorrectionController{
+		registry:   registry,
+		corrector:  corrector,
+		audit:      audit,
+		quarantine: quarantine,
+	}
+}
+
+// PurgeCache godoc
+// @Summary Purge L1 cache entries of provider
+// @Description Entries are selected by date range and currency pair, latest rates are purged only without date range.
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param start_date query string false "The first date (format YYYY-MM-DD)"
+// @Param end_date query string false "The last date (format YYYY-MM-DD)"
+// @Param base query string false "Base currency"
+// @Param symbol query string false "Quoted currency"
+// @Param rate_type query string false "Rate type" Enums(mid, bid, ask)
+// @Success 200 {object} model.CorrectionApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/cache/{provider} [delete]
+func (controller *CorrectionController) PurgeCache() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		filter, err := controller.parseFilter(c, false)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		purged, err := controller.corrector.PurgeCache(auth.GetActor(c), filter)
+		controller.respond(c, filter.ProviderCode, entity.AuditActionPurgeCache, purged, err)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// DeleteRates godoc
+// @Summary Delete stored historical rates of provider for date range
+// @Description Rows are deleted from L2 cache and purged from L1 cache.
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param start_date query string true "The first date (format YYYY-MM-DD)"
+// @Param end_date query string true "The last date (format YYYY-MM-DD)"
+// @Param base query string false "Base currency"
+// @Param symbol query string false "Quoted currency"
+// @Param rate_type query string false "Rate type" Enums(mid, bid, ask)
+// @Success 200 {object} model.CorrectionApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/rates/{provider} [delete]
+func (controller *CorrectionController) DeleteRates() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		filter, err := controller.parseFilter(c, true)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		deleted, err := controller.corrector.DeleteRates(auth.GetActor(c), filter)
+		controller.respond(c, filter.ProviderCode, entity.AuditActionDeleteRates, deleted, err)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// OverwriteRates godoc
+// @Summary Overwrite stored historical rates of provider for date
+// @Description Passed rates replace stored ones in L2 cache and are purged from L1 cache. Sanity checks are skipped.
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param date path string true "Date (format YYYY-MM-DD)"
+// @Param rates body model.CorrectRatesRequest true "Corrected rates"
+// @Success 200 {object} model.CorrectionApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/rates/{provider}/{date} [put]
+func (controller *CorrectionController) OverwriteRates() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		var request model.CorrectRatesRequest
+		prov, date, err := controller.parseProviderDate(c)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		if err = c.ShouldBindJSON(&request); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. "+err.Error()))
+			return
+		}
+		request.Base = strings.ToUpper(request.Base)
+		rates := make(map[string]decimal.Decimal)
+		for currency, rate := range request.Rates {
+			if !rate.IsPositive() {
+				controller.respondError(c, customerror.NewBadRequestError("error parsing request. Rate of "+currency+" should be positive"))
+				return
+			}
+			rates[strings.ToUpper(currency)] = rate
+		}
+		rateType := request.RateType
+		if rateType != "" && rateType != util.RateTypeMid && rateType != util.RateTypeBid && rateType != util.RateTypeAsk {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Invalid rate_type"))
+			return
+		}
+		if rateType != "" && !prov.GetCapabilities().SupportsRateType(rateType) {
+			controller.respondError(c, customerror.NewUnprocessableError("rate type "+rateType+" is not published by provider "+prov.GetCode()))
+			return
+		}
+		saved, err := controller.corrector.OverwriteRates(auth.GetActor(c), prov, date, request.Base, request.RateType, rates)
+		controller.respond(c, prov.GetCode(), entity.AuditActionOverwriteRates, saved, err)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// RefetchRates godoc
+// @Summary Re-fetch historical rates of provider for date
+// @Description Rates changed by provider are revised, prior versions are kept in revisions.
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param date path string true "Date (format YYYY-MM-DD)"
+// @Success 200 {object} model.CorrectionApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 502 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Failure 504 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/rates/{provider}/{date}/refetch [post]
+func (controller *CorrectionController) RefetchRates() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		prov, date, err := controller.parseProviderDate(c)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		deleted, err := controller.corrector.RefetchRates(auth.GetActor(c), prov, date)
+		controller.respond(c, prov.GetCode(), entity.AuditActionRefetchRates, deleted, err)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// Quarantine godoc
+// @Summary List days of provider with rates quarantined by ingestion-time sanity checks
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Success 200 {object} model.QuarantineApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/quarantine/{provider} [get]
+func (controller *CorrectionController) Quarantine() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		prov, err := controller.registry.GetProvider(c.Param("provider"))
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		entities, err := controller.quarantine.FindAll(prov.GetCode())
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		response := model.QuarantineApiResponse{
+			Success:  true,
+			Provider: prov.GetCode(),
+			Days:     make([]model.QuarantinedDay, 0),
+		}
+		for _, e := range entities {
+			last := len(response.Days) - 1
+			if last < 0 || response.Days[last].Date != e.RateDate {
+				response.Days = append(response.Days, model.QuarantinedDay{
+					Date:        e.RateDate,
+					Reason:      e.Reason,
+					CreatedTime: e.CreatedTime.Unix(),
+				})
+				last++
+			}
+			response.Days[last].Pairs++
+		}
+		c.JSON(http.StatusOK, response)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// ReleaseQuarantine godoc
+// @Summary Release quarantined rates of provider for date
+// @Description Reviewed rates are saved to L2 cache as provider published them and purged from L1 cache.
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param date path string true "Date (format YYYY-MM-DD)"
+// @Success 200 {object} model.CorrectionApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/quarantine/{provider}/{date}/release [post]
+func (controller *CorrectionController) ReleaseQuarantine() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		prov, date, err := controller.parseProviderDate(c)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		released, err := controller.corrector.ReleaseQuarantine(auth.GetActor(c), prov, date)
+		controller.respond(c, prov.GetCode(), entity.AuditActionReleaseQuarantine, released, err)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// DiscardQuarantine godoc
+// @Summary Discard quarantined rates of provider for date
+// @Description Rates are fetched from provider and checked again on the next preload or request.
+// @Produce json
+// @Param provider path string true "Provider" Enums(emirates, fixer)
+// @Param date path string true "Date (format YYYY-MM-DD)"
+// @Success 200 {object} model.CorrectionApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/quarantine/{provider}/{date} [delete]
+func (controller *CorrectionController) DiscardQuarantine() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		prov, date, err := controller.parseProviderDate(c)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		deleted, err := controller.corrector.DiscardQuarantine(auth.GetActor(c), prov, date)
+		controller.respond(c, prov.GetCode(), entity.AuditActionDiscardQuarantine, deleted, err)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// Audit godoc
+// @Summary List the latest changes of cached rates made via Admin API
+// @Produce json
+// @Param provider query string false "Provider" Enums(emirates, fixer)
+// @Param limit query integer false "Number of records, 100 by default"
+// @Success 200 {object} model.AuditApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 503 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /admin/audit [get]
+func (controller *CorrectionController) Audit() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(auditListLimit)))
+		if err != nil || limit <= 0 {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. Invalid limit"))
+			return
+		}
+		records, err := controller.audit.FindAll(c.Query("provider"), limit)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		response := model.AuditApiResponse{
+			Success: true,
+			Records: make([]model.AuditRecord, 0, len(records)),
+		}
+		for _, record := range records {
+			response.Records = append(response.Records, model.AuditRecord{
+				ID:          record.ID,
+				Actor:       record.Actor,
+				Action:      record.Action,
+				Provider:    record.Provider,
+				Params:      []byte(record.Params),
+				Status:      record.Status,
+				Affected:    record.Affected,
+				Error:       record.Error,
+				CreatedTime: record.CreatedTime.Unix(),
+			})
+		}
+		c.JSON(http.StatusOK, response)
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// parseFilter returns filter of cached rates from request. Date range is required if dates is true
+func (controller *CorrectionController) parseFilter(c *gin.Context, dates bool) (model.RatesFilter, error) {
+	var (
+		err    error
+		prov   provider.RatesProvider
+		filter model.RatesFilter
+	)
+	if prov, err = controller.registry.GetProvider(c.Param("provider")); err != nil {
+		return filter, err
+	}
+	filter.ProviderCode = prov.GetCode()
+	for name, date := range map[string]*time.Time{"start_date": &filter.StartDate, "end_date": &filter.EndDate} {
+		dateStr := c.Query(name)
+		if dateStr == "" {
+			if dates {
+				return filter, customerror.NewBadRequestError("error parsing request. " + name + " is required")
+			}
+			continue
+		}
+		if *date, err = time.ParseInLocation(util.DateFormatEu, dateStr, time.UTC); err != nil {
+			return filter, customerror.NewBadRequestError("error parsing request. Invalid " + name + ". " + err.Error())
+		}
+	}
+	filter.BaseCurrency = strings.ToUpper(c.Query("base"))
+	filter.QuotedCurrency = strings.ToUpper(c.Query("symbol"))
+	filter.RateType = c.Query("rate_type")
+	return filter, nil
+}
+
+// parseProviderDate returns provider and date from request path
+func (controller *CorrectionController) parseProviderDate(c *gin.Context) (provider.RatesProvider, time.Time, error) {
+	prov, err := controller.registry.GetProvider(c.Param("provider"))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	date, err := time.ParseInLocation(util.DateFormatEu, c.Param("date"), time.UTC)
+	if err != nil {
+		return nil, time.Time{}, customerror.NewBadRequestError("error parsing request. Invalid date. " + err.Error())
+	}
+	return prov, date, nil
+}
+
+// respond writes result of action
+func (controller *CorrectionController) respond(c *gin.Context, providerCode string, action string, affected int64, err error) {
+	if err != nil {
+		controller.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.CorrectionApiResponse{
+		Success:  true,
+		Provider: providerCode,
+		Action:   action,
+		Affected: affected,
+	})
+}
+
+// respondError writes failed response with HTTP status and machine-readable code of passed typed error
+func (controller *CorrectionController) respondError(c *gin.Context, err error) {
+	response := model.NewFailedApiResponseFromError(err)
+	c.JSON(response.Error.Code, response)
+}
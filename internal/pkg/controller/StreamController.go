@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/stream"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"io"
+	"time"
+)
+
+// StreamController is controller of streaming of latest rates changes
+type StreamController struct {
+	config *model.ApplicationConfig
+	hub    *stream.Hub
+}
+
+// NewStreamController is the constructor
+func NewStreamController(config *model.ApplicationConfig, hub *stream.Hub) *StreamController {
+	return &StreamController{
+		config: config,
+		hub:    hub,
+	}
+}
+
+// Latest godoc
+// @Summary Stream changes of latest currency rates (Server-Sent Events)
+// @Description The first "rates" event contains all subscribed rates, the next ones - only rates changed since
+// @Description the previous event, or all rates if their date is changed. Rates are polled once per provider,
+// @Description base currency, symbols and rate type through cache, so polling is shared by all subscribers.
+// @Produce text/event-stream
+// @Param provider path string false "Provider" Enums(emirates, fixer, composite, consensus)
+// @Param base query string true "Base currency"
+// @Param symbols query string true "Quoted currencies, comme separated"
+// @Param rate_type query string false "Rate type (side), mid by default" Enums(mid, bid, ask)
+// @Param format query string false "Format of rates in response, number by default" Enums(number, string)
+// @Success 200 {object} model.SuccessApiResponse
+// @Failure 400 {object} model.FailedApiResponse
+// @Failure 404 {object} model.FailedApiResponse
+// @Failure 422 {object} model.FailedApiResponse
+// @Failure 401 {object} model.FailedApiResponse
+// @Failure 403 {object} model.FailedApiResponse
+// @Failure 429 {object} model.FailedApiResponse
+// @Security ApiKeyAuth
+// @Router /stream/{provider} [get]
+func (controller *StreamController) Latest() gin.HandlerFunc {
+	fn := func(c *gin.Context) {
+		var serviceRequest = model.RatesRequest{}
+
+		// Parse HTTP request params
+		if err := serviceRequest.FromGinContext(c, controller.config, util.EndpointLatest); err != nil {
+			controller.respondError(c, customerror.NewBadRequestError("error parsing request. "+err.Error()))
+			return
+		}
+		if !serviceRequest.At.IsZero() {
+			controller.respondError(c, customerror.NewBadRequestError("instant (at) is supported by historical and snapshot endpoints only"))
+			return
+		}
+
+		// Subscribe
+		subscription, err := controller.hub.Subscribe(serviceRequest)
+		if err != nil {
+			controller.respondError(c, err)
+			return
+		}
+		defer subscription.Close()
+
+		// Stream events until client disconnects
+		heartbeat := time.NewTicker(controller.getHeartbeat())
+		defer heartbeat.Stop()
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-store")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case <-heartbeat.C:
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err == nil
+			case update, ok := <-subscription.Updates():
+				if !ok {
+					// Subscriber is too slow, client should reconnect
+					return false
+				}
+				request := update.Request
+				request.Format = serviceRequest.Format
+				c.SSEvent("rates", model.NewSuccessApiResponse(request, update.Response))
+				return true
+			}
+		})
+	}
+	return gin.HandlerFunc(fn)
+}
+
+// getHeartbeat returns interval of keep-alive comments, which prevent proxies from closing idle stream
+func (controller *StreamController) getHeartbeat() time.Duration {
+	if controller.config.Stream.Heartbeat > 0 {
+		return time.Duration(controller.config.Stream.Heartbeat) * time.Second
+	}
+	return 15 * time.Second
+}
+
+// respondError writes failed response with HTTP status and machine-readable code of passed typed error
+func (controller *StreamController) respondError(c *gin.Context, err error) {
+	response := model.NewFailedApiResponseFromError(err)
+	c.JSON(response.Error.Code, response)
+}
@@ -0,0 +1,278 @@
+// Package correction implements invalidation and manual correction of cached rates
+package correction
+
+import (
+	"encoding/json"
+	cache_store "github.com/netandreus/go-forex-rates/internal/pkg/cache/store"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"time"
+)
+
+// Corrector purges L1 cache entries, deletes and overwrites L2 cache rows, re-fetches rates from provider
+// and releases quarantined rates. Stored averages of changed periods are deleted. Every change is recorded
+// to audit log before it is made, so nothing is changed if audit log record is not saved. The record is updated
+// with outcome of the change afterwards.
+// L1 cache is purged on this instance only, other instances serve their L1 entries till they expire
+type Corrector struct {
+	l1         *gocache.Cache
+	l2         *cache_store.MySQLStore
+	audit      *repository.AuditRepository
+	quarantine *repository.QuarantineRepository
+	averages   *repository.AverageRepository
+}
+
+// BuildCorrector /* *Corrector
+func BuildCorrector(
+	l1 *gocache.Cache,
+	l2 *cache_store.MySQLStore,
+	audit *repository.AuditRepository,
+	quarantine *repository.QuarantineRepository,
+	averages *repository.AverageRepository) (*Corrector, error) {
+	return NewCorrector(l1, l2, audit, quarantine, averages), nil
+}
+
+// NewCorrector constructor
+func NewCorrector(
+	l1 *gocache.Cache,
+	l2 *cache_store.MySQLStore,
+	audit *repository.AuditRepository,
+	quarantine *repository.QuarantineRepository,
+	averages *repository.AverageRepository) *Corrector {
+	return &Corrector{
+		l1:         l1,
+		l2:         l2,
+		audit:      audit,
+		quarantine: quarantine,
+		averages:   averages,
+	}
+}
+
+// PurgeCache deletes L1 cache entries selected by filter. Returns number of deleted entries
+func (c *Corrector) PurgeCache(actor string, filter model.RatesFilter) (int64, error) {
+	record, err := c.start(actor, entity.AuditActionPurgeCache, filter.ProviderCode, c.getFilterParams(filter))
+	if err != nil {
+		return 0, err
+	}
+	purged := c.purgeL1(filter)
+	return purged, c.finish(record, purged, nil)
+}
+
+// DeleteRates deletes L2 cache rows and L1 cache entries selected by filter. Returns number of deleted pairs
+func (c *Corrector) DeleteRates(actor string, filter model.RatesFilter) (int64, error) {
+	record, err := c.start(actor, entity.AuditActionDeleteRates, filter.ProviderCode, c.getFilterParams(filter))
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := c.l2.DeleteRates(filter)
+	c.invalidate(filter)
+	return deleted, c.finish(record, deleted, err)
+}
+
+// OverwriteRates replaces stored historical rates of date and purges them from L1 cache.
+// Returns number of saved pairs
+func (c *Corrector) OverwriteRates(
+	actor string,
+	p provider.RatesProvider,
+	date time.Time,
+	baseCurrency string,
+	rateType string,
+	rates map[string]decimal.Decimal) (int64, error) {
+	key := model.RatesRequest{
+		Endpoint:     util.EndpointHistorical,
+		ProviderCode: p.GetCode(),
+		Date:         date,
+		BaseCurrency: baseCurrency,
+		RateType:     rateType,
+	}
+	filter := model.RatesFilter{
+		ProviderCode: p.GetCode(),
+		StartDate:    date,
+		EndDate:      date,
+		BaseCurrency: baseCurrency,
+		RateType:     key.GetRateType(),
+	}
+	params := c.getFilterParams(filter)
+	params["rates"] = rates
+	record, err := c.start(actor, entity.AuditActionOverwriteRates, p.GetCode(), params)
+	if err != nil {
+		return 0, err
+	}
+	saved, err := c.l2.Overwrite(key, model.RatesResponse{Rates: rates, Timestamp: time.Now().Unix()})
+	c.invalidate(filter)
+	return saved, c.finish(record, saved, err)
+}
+
+// RefetchRates re-fetches rates of date from provider. Stored rates changed by provider are revised,
+// prior versions are kept in revisions. If provider does not preload rates, stored rates are deleted
+// and fetched on the next request. Returns number of fetched or deleted pairs
+func (c *Corrector) RefetchRates(actor string, p provider.RatesProvider, date time.Time) (int64, error) {
+	var affected int64
+	filter := model.RatesFilter{ProviderCode: p.GetCode(), StartDate: date, EndDate: date}
+	record, err := c.start(actor, entity.AuditActionRefetchRates, p.GetCode(), c.getFilterParams(filter))
+	if err != nil {
+		return 0, err
+	}
+	direct, reverse, _, err := p.PreloadRates(date, true)
+	if err == nil {
+		affected = int64(len(direct) + len(reverse))
+		if affected == 0 {
+			affected, err = c.l2.DeleteRates(filter)
+		}
+	}
+	c.invalidate(filter)
+	return affected, c.finish(record, affected, err)
+}
+
+// ReleaseQuarantine approves quarantined rates of date: they are saved to L2 cache as provider published them
+// and purged from L1 cache. Returns number of released pairs
+func (c *Corrector) ReleaseQuarantine(actor string, p provider.RatesProvider, date time.Time) (int64, error) {
+	var released int64
+	filter := model.RatesFilter{ProviderCode: p.GetCode(), StartDate: date, EndDate: date}
+	record, err := c.start(actor, entity.AuditActionReleaseQuarantine, p.GetCode(), c.getFilterParams(filter))
+	if err != nil {
+		return 0, err
+	}
+	entities, err := c.quarantine.FindByDate(p.GetCode(), date)
+	if err == nil && len(entities) == 0 {
+		err = customerror.NewNotFoundError("rates of provider " + p.GetCode() + " for " + date.Format(util.DateFormatEu) + " are not quarantined")
+	}
+	if err == nil {
+		// Quarantined rates of the day grouped by base currency and rate type
+		keys := make(map[string]model.RatesRequest)
+		values := make(map[string]model.RatesResponse)
+		for _, e := range entities {
+			groupKey := e.BaseCurrency + "|" + e.RateType
+			if _, ok := keys[groupKey]; !ok {
+				keys[groupKey] = model.RatesRequest{
+					Endpoint:     util.EndpointHistorical,
+					ProviderCode: p.GetCode(),
+					Date:         date,
+					BaseCurrency: e.BaseCurrency,
+					RateType:     e.RateType,
+				}
+				values[groupKey] = model.RatesResponse{
+					Rates:     make(map[string]decimal.Decimal),
+					Timestamp: e.ProviderGeneratedTime.Unix(),
+				}
+			}
+			values[groupKey].Rates[e.QuotedCurrency] = e.Value
+		}
+		for groupKey, key := range keys {
+			saved, saveErr := c.l2.Overwrite(key, values[groupKey])
+			released += saved
+			if saveErr != nil {
+				err = saveErr
+				break
+			}
+		}
+	}
+	if err == nil {
+		_, err = c.quarantine.Delete(p.GetCode(), date)
+	}
+	c.invalidate(filter)
+	return released, c.finish(record, released, err)
+}
+
+// DiscardQuarantine deletes quarantined rates of date, they will be fetched and checked again on the next preload
+// or request. Returns number of deleted pairs
+func (c *Corrector) DiscardQuarantine(actor string, p provider.RatesProvider, date time.Time) (int64, error) {
+	filter := model.RatesFilter{ProviderCode: p.GetCode(), StartDate: date, EndDate: date}
+	record, err := c.start(actor, entity.AuditActionDiscardQuarantine, p.GetCode(), c.getFilterParams(filter))
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := c.quarantine.Delete(p.GetCode(), date)
+	return deleted, c.finish(record, deleted, err)
+}
+
+// invalidate purges L1 cache entries and deletes stored averages of rates selected by filter after they were
+// changed in L2 cache. Failed deletion of averages is logged only, as the change is made already
+func (c *Corrector) invalidate(filter model.RatesFilter) {
+	c.purgeL1(filter)
+	if _, err := c.averages.DeleteByFilter(filter); err != nil {
+		logger.LogError("Averages of changed rates of provider "+filter.ProviderCode+" are not deleted. "+err.Error(), "CACHE")
+	}
+}
+
+// purgeL1 deletes L1 cache entries selected by filter. Keys of L1 cache are JSON of rates request
+func (c *Corrector) purgeL1(filter model.RatesFilter) int64 {
+	var purged int64
+	for key := range c.l1.Items() {
+		request := model.RatesRequest{}
+		if err := request.FromString(key); err != nil {
+			continue
+		}
+		if filter.MatchRequest(request) {
+			c.l1.Delete(key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// getFilterParams returns filter as audit log parameters
+func (c *Corrector) getFilterParams(filter model.RatesFilter) map[string]interface{} {
+	params := map[string]interface{}{}
+	if !filter.StartDate.IsZero() {
+		params["start_date"] = filter.StartDate.Format(util.DateFormatEu)
+	}
+	if !filter.EndDate.IsZero() {
+		params["end_date"] = filter.EndDate.Format(util.DateFormatEu)
+	}
+	if filter.BaseCurrency != "" {
+		params["base"] = filter.BaseCurrency
+	}
+	if filter.QuotedCurrency != "" {
+		params["symbol"] = filter.QuotedCurrency
+	}
+	if filter.RateType != "" {
+		params["rate_type"] = filter.RateType
+	}
+	return params
+}
+
+// start saves pending audit log record of action before the change is made
+func (c *Corrector) start(actor string, action string, providerCode string, params map[string]interface{}) (*entity.AuditLog, error) {
+	paramsJson, _ := json.Marshal(params)
+	record := &entity.AuditLog{
+		Actor:       actor,
+		Action:      action,
+		Provider:    providerCode,
+		Params:      string(paramsJson),
+		Status:      entity.AuditStatusPending,
+		CreatedTime: time.Now().UTC(),
+	}
+	if err := c.audit.Create(record); err != nil {
+		logger.LogError("Audit log record is not saved, "+action+" is not made. "+err.Error(), "AUDIT")
+		return nil, err
+	}
+	return record, nil
+}
+
+// finish updates audit log record with outcome of action. Returns error of action: the change is made already,
+// so failed update of the record is logged only and the record stays pending
+func (c *Corrector) finish(record *entity.AuditLog, affected int64, err error) error {
+	record.Affected = affected
+	record.Status = entity.AuditStatusDone
+	if err != nil {
+		record.Status = entity.AuditStatusFailed
+		record.Error = err.Error()
+	}
+	message := record.Actor + " " + record.Action + " " + record.Provider + " " + record.Params +
+		", affected: " + strconv.FormatInt(affected, 10)
+	if saveErr := c.audit.Save(record); saveErr != nil {
+		logger.LogError("Audit log record "+strconv.Itoa(int(record.ID))+" is not updated: "+message+". "+saveErr.Error(), "AUDIT")
+		return err
+	}
+	logger.LogSuccess(message, "AUDIT")
+	return err
+}
@@ -1,5 +1,7 @@
 package customerror
 
+import "net/http"
+
 // BadRequestError represents bad RatesRequest error
 type BadRequestError struct {
 	message string
@@ -10,6 +12,16 @@ func (m *BadRequestError) Error() string {
 	return m.message
 }
 
+// GetStatus returns HTTP status code
+func (m *BadRequestError) GetStatus() int {
+	return http.StatusBadRequest
+}
+
+// GetCode returns machine-readable error code
+func (m *BadRequestError) GetCode() string {
+	return CodeBadRequest
+}
+
 // NewBadRequestError error constructor
 func NewBadRequestError(message string) *BadRequestError {
 	return &BadRequestError{
@@ -1,5 +1,7 @@
 package customerror
 
+import "net/http"
+
 // DatabaseError represents database error
 type DatabaseError struct {
 	message string
@@ -10,6 +12,16 @@ func (m *DatabaseError) Error() string {
 	return m.message
 }
 
+// GetStatus returns HTTP status code
+func (m *DatabaseError) GetStatus() int {
+	return http.StatusServiceUnavailable
+}
+
+// GetCode returns machine-readable error code
+func (m *DatabaseError) GetCode() string {
+	return CodeDatabase
+}
+
 // NewDatabaseError error constructor
 func NewDatabaseError(message string) *DatabaseError {
 	return &DatabaseError{
@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// ForbiddenError represents request, which is not allowed for API access key
+type ForbiddenError struct {
+	message string
+}
+
+// Error returns error message
+func (m *ForbiddenError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *ForbiddenError) GetStatus() int {
+	return http.StatusForbidden
+}
+
+// GetCode returns machine-readable error code
+func (m *ForbiddenError) GetCode() string {
+	return CodeForbidden
+}
+
+// NewForbiddenError error constructor
+func NewForbiddenError(message string) *ForbiddenError {
+	return &ForbiddenError{
+		message: message,
+	}
+}
@@ -0,0 +1,78 @@
+// Package customerror contains typed application errors, which can be mapped to HTTP statuses
+package customerror
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Stable machine-readable error codes
+const (
+	CodeBadRequest      = "bad_request"
+	CodeNotFound        = "not_found"
+	CodeUnknownProvider = "unknown_provider"
+	CodeUnprocessable   = "unprocessable_request"
+	CodeDatabase        = "database_error"
+	CodeProvider        = "provider_error"
+	CodeUnavailable     = "provider_unavailable"
+	CodeTimeout         = "provider_timeout"
+	CodeInternal        = "internal_error"
+	CodeUnauthorized    = "invalid_access_key"
+	CodeForbidden       = "access_restricted"
+	CodeRateLimit       = "rate_limit_reached"
+	CodeQuota           = "usage_limit_reached"
+)
+
+// HttpError is error with HTTP status and machine-readable code
+type HttpError interface {
+	error
+
+	// GetStatus returns HTTP status code
+	GetStatus() int
+
+	// GetCode returns machine-readable error code
+	GetCode() string
+}
+
+// GetStatus returns HTTP status code of passed error, 500 for untyped errors
+func GetStatus(err error) int {
+	var httpError HttpError
+	if errors.As(err, &httpError) {
+		return httpError.GetStatus()
+	}
+	return http.StatusInternalServerError
+}
+
+// GetCode returns machine-readable code of passed error, internal_error for untyped errors
+func GetCode(err error) string {
+	var httpError HttpError
+	if errors.As(err, &httpError) {
+		return httpError.GetCode()
+	}
+	return CodeInternal
+}
+
+// IsRetryable returns true if request failed with passed error can be retried later
+func IsRetryable(err error) bool {
+	switch GetStatus(err) {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsNotFound returns true if passed error is NotFoundError
+func IsNotFound(err error) bool {
+	var notFoundError *NotFoundError
+	return errors.As(err, &notFoundError)
+}
+
+// NewProviderRequestError classifies failed provider API request error as TimeoutError or UnavailableError
+func NewProviderRequestError(providerCode string, err error) HttpError {
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return NewTimeoutError("provider " + providerCode + " request timeout. " + err.Error())
+	}
+	return NewUnavailableError("provider " + providerCode + " is unavailable. " + err.Error())
+}
@@ -1,5 +1,7 @@
 package customerror
 
+import "net/http"
+
 // NotFoundError is rates not found error
 type NotFoundError struct {
 	message string
@@ -10,6 +12,16 @@ func (m *NotFoundError) Error() string {
 	return m.message
 }
 
+// GetStatus returns HTTP status code
+func (m *NotFoundError) GetStatus() int {
+	return http.StatusNotFound
+}
+
+// GetCode returns machine-readable error code
+func (m *NotFoundError) GetCode() string {
+	return CodeNotFound
+}
+
 // NewNotFoundError error constructor
 func NewNotFoundError(message string) *NotFoundError {
 	return &NotFoundError{
@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// ProviderError represents invalid or failed provider API response
+type ProviderError struct {
+	message string
+}
+
+// Error returns error message
+func (m *ProviderError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *ProviderError) GetStatus() int {
+	return http.StatusBadGateway
+}
+
+// GetCode returns machine-readable error code
+func (m *ProviderError) GetCode() string {
+	return CodeProvider
+}
+
+// NewProviderError error constructor
+func NewProviderError(message string) *ProviderError {
+	return &ProviderError{
+		message: message,
+	}
+}
@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// QuotaError represents exceeded monthly quota of API access key
+type QuotaError struct {
+	message string
+}
+
+// Error returns error message
+func (m *QuotaError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *QuotaError) GetStatus() int {
+	return http.StatusTooManyRequests
+}
+
+// GetCode returns machine-readable error code
+func (m *QuotaError) GetCode() string {
+	return CodeQuota
+}
+
+// NewQuotaError error constructor
+func NewQuotaError(message string) *QuotaError {
+	return &QuotaError{
+		message: message,
+	}
+}
@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// RateLimitError represents exceeded rate limit of API access key
+type RateLimitError struct {
+	message string
+}
+
+// Error returns error message
+func (m *RateLimitError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *RateLimitError) GetStatus() int {
+	return http.StatusTooManyRequests
+}
+
+// GetCode returns machine-readable error code
+func (m *RateLimitError) GetCode() string {
+	return CodeRateLimit
+}
+
+// NewRateLimitError error constructor
+func NewRateLimitError(message string) *RateLimitError {
+	return &RateLimitError{
+		message: message,
+	}
+}
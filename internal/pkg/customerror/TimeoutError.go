@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// TimeoutError represents provider API request timeout
+type TimeoutError struct {
+	message string
+}
+
+// Error returns error message
+func (m *TimeoutError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *TimeoutError) GetStatus() int {
+	return http.StatusGatewayTimeout
+}
+
+// GetCode returns machine-readable error code
+func (m *TimeoutError) GetCode() string {
+	return CodeTimeout
+}
+
+// NewTimeoutError error constructor
+func NewTimeoutError(message string) *TimeoutError {
+	return &TimeoutError{
+		message: message,
+	}
+}
@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// UnauthorizedError represents missing or invalid API access key
+type UnauthorizedError struct {
+	message string
+}
+
+// Error returns error message
+func (m *UnauthorizedError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *UnauthorizedError) GetStatus() int {
+	return http.StatusUnauthorized
+}
+
+// GetCode returns machine-readable error code
+func (m *UnauthorizedError) GetCode() string {
+	return CodeUnauthorized
+}
+
+// NewUnauthorizedError error constructor
+func NewUnauthorizedError(message string) *UnauthorizedError {
+	return &UnauthorizedError{
+		message: message,
+	}
+}
@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// UnavailableError represents provider API outage
+type UnavailableError struct {
+	message string
+}
+
+// Error returns error message
+func (m *UnavailableError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *UnavailableError) GetStatus() int {
+	return http.StatusServiceUnavailable
+}
+
+// GetCode returns machine-readable error code
+func (m *UnavailableError) GetCode() string {
+	return CodeUnavailable
+}
+
+// NewUnavailableError error constructor
+func NewUnavailableError(message string) *UnavailableError {
+	return &UnavailableError{
+		message: message,
+	}
+}
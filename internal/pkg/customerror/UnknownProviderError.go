@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// UnknownProviderError is request to not registered provider error
+type UnknownProviderError struct {
+	message string
+}
+
+// Error returns error message
+func (m *UnknownProviderError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *UnknownProviderError) GetStatus() int {
+	return http.StatusNotFound
+}
+
+// GetCode returns machine-readable error code
+func (m *UnknownProviderError) GetCode() string {
+	return CodeUnknownProvider
+}
+
+// NewUnknownProviderError error constructor
+func NewUnknownProviderError(message string) *UnknownProviderError {
+	return &UnknownProviderError{
+		message: message,
+	}
+}
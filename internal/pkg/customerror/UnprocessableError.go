@@ -0,0 +1,30 @@
+package customerror
+
+import "net/http"
+
+// UnprocessableError represents valid request, which provider can not serve (unsupported currency, date etc.)
+type UnprocessableError struct {
+	message string
+}
+
+// Error returns error message
+func (m *UnprocessableError) Error() string {
+	return m.message
+}
+
+// GetStatus returns HTTP status code
+func (m *UnprocessableError) GetStatus() int {
+	return http.StatusUnprocessableEntity
+}
+
+// GetCode returns machine-readable error code
+func (m *UnprocessableError) GetCode() string {
+	return CodeUnprocessable
+}
+
+// NewUnprocessableError error constructor
+func NewUnprocessableError(message string) *UnprocessableError {
+	return &UnprocessableError{
+		message: message,
+	}
+}
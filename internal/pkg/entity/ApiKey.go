@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// ApiKey represents API access key with its limits
+type ApiKey struct {
+	// Id
+	ID uint
+
+	// Unique name of key owner
+	Name string
+
+	// Access key
+	AccessKey string `json:"access_key"`
+
+	// Max number of requests per minute (0 - unlimited)
+	RateLimit int `json:"rate_limit"`
+
+	// Max number of requests per calendar month (0 - unlimited)
+	MonthlyQuota int `json:"monthly_quota"`
+
+	// Comma separated codes of allowed providers (empty - all providers)
+	Providers string
+
+	// Allow access to Admin API
+	Admin bool
+
+	// Disabled key is rejected
+	Enabled bool
+
+	// Key creation time (UTC)
+	CreatedTime time.Time `json:"created_time"`
+}
+
+// TableName returns MySQL table name
+func (k ApiKey) TableName() string {
+	return "api_key"
+}
+
+// ApiKeyUsage represents number of requests made with API access key in calendar month
+type ApiKeyUsage struct {
+	// Name of key owner
+	Name string `gorm:"primaryKey"`
+
+	// Month (format YYYY-MM)
+	Month string `gorm:"primaryKey"`
+
+	// Number of requests
+	Requests int
+
+	// The last update time (UTC)
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+// TableName returns MySQL table name
+func (u ApiKeyUsage) TableName() string {
+	return "api_key_usage"
+}
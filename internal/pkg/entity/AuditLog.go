@@ -0,0 +1,56 @@
+package entity
+
+import "time"
+
+// Audit log actions
+const (
+	AuditActionPurgeCache     = "purge_cache"
+	AuditActionDeleteRates    = "delete_rates"
+	AuditActionOverwriteRates = "overwrite_rates"
+	AuditActionRefetchRates   = "refetch_rates"
+
+	AuditActionReleaseQuarantine = "release_quarantine"
+	AuditActionDiscardQuarantine = "discard_quarantine"
+)
+
+// Audit log record statuses
+const (
+	AuditStatusPending = "pending"
+	AuditStatusDone    = "done"
+	AuditStatusFailed  = "failed"
+)
+
+// AuditLog represents change of cached rates made via Admin API
+type AuditLog struct {
+	// Id
+	ID uint
+
+	// Name of API access key owner, who made the change
+	Actor string
+
+	// Action: purge_cache, delete_rates, overwrite_rates, refetch_rates, release_quarantine or discard_quarantine
+	Action string
+
+	// Provider code
+	Provider string
+
+	// Action parameters (JSON)
+	Params string
+
+	// Status: pending (record is saved before the change), done or failed
+	Status string
+
+	// Number of affected cache entries or pairs
+	Affected int64
+
+	// Error message, empty if action succeeded
+	Error string
+
+	// Action time (UTC)
+	CreatedTime time.Time `json:"created_time"`
+}
+
+// TableName returns MySQL table name
+func (l AuditLog) TableName() string {
+	return "audit_log"
+}
@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
 
 // CurrencyRate represents one currency pair exchange rate
 type CurrencyRate struct {
@@ -14,7 +17,10 @@ type CurrencyRate struct {
 	QuotedCurrency string
 
 	// Rate value
-	Value float64
+	Value decimal.Decimal `gorm:"type:decimal(30,12)"`
+
+	// Rate type: mid, bid or ask
+	RateType string `json:"rate_type"`
 
 	// The date on which the rates are requested. String is used so that there is no conversion to the server timezone
 	RateDate string `json:"rate_date"`
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// CurrencyRateAverage represents statistics of one currency pair exchange rates over closed accounting period
+type CurrencyRateAverage struct {
+	// Id
+	ID uint
+
+	// Provider of currency exchange rates
+	Provider string
+
+	// Base currency of currency pair
+	BaseCurrency string
+
+	// Quoted currency of currency pair
+	QuotedCurrency string
+
+	// Rate type: mid, bid or ask
+	RateType string `json:"rate_type"`
+
+	// Accounting period: YYYY-MM, YYYY-Qn or YYYY
+	Period string
+
+	// Are only rates of provider's publication days used
+	PublicationDays bool `json:"publication_days"`
+
+	// Arithmetic mean of rates
+	Mean decimal.Decimal `gorm:"type:decimal(30,12)"`
+
+	// Minimal rate
+	Min decimal.Decimal `gorm:"type:decimal(30,12)"`
+
+	// Maximal rate
+	Max decimal.Decimal `gorm:"type:decimal(30,12)"`
+
+	// Number of observations (stored daily rates) used
+	Count int
+
+	// Calculation time (UTC)
+	CalculatedTime time.Time `json:"calculated_time"`
+}
+
+// TableName returns MySQL table name
+func (r CurrencyRateAverage) TableName() string {
+	return "currency_rate_average"
+}
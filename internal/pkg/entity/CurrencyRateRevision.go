@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// CurrencyRateRevision represents prior version of historical exchange rate of one currency pair,
+// which was replaced by corrected rate republished by provider
+type CurrencyRateRevision struct {
+	// Id
+	ID uint
+
+	// Provider of this currency exchange rate
+	Provider string
+
+	// Base currency of currency pair
+	BaseCurrency string
+
+	// Quoted currency of currency pair
+	QuotedCurrency string
+
+	// Rate value
+	Value decimal.Decimal `gorm:"type:decimal(30,12)"`
+
+	// Rate type: mid, bid or ask
+	RateType string `json:"rate_type"`
+
+	// The date of provider's publication the rate belongs to
+	RateDate string `json:"rate_date"`
+
+	// Provider generated rate time (UTC)
+	ProviderGeneratedTime time.Time `json:"provider_generated_time"`
+
+	// Time since the rate was known (UTC)
+	KnownFromTime time.Time `json:"known_from_time"`
+
+	// Time since the rate was replaced by corrected one (UTC)
+	KnownUntilTime time.Time `json:"known_until_time"`
+}
+
+// TableName returns MySQL table name
+func (r CurrencyRateRevision) TableName() string {
+	return "currency_rate_revision"
+}
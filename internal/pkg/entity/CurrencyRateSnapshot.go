@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// CurrencyRateSnapshot represents latest exchange rate of one currency pair, fetched from provider at some instant
+type CurrencyRateSnapshot struct {
+	// Id
+	ID uint
+
+	// Provider of this currency exchange rate
+	Provider string
+
+	// Base currency of currency pair
+	BaseCurrency string
+
+	// Quoted currency of currency pair
+	QuotedCurrency string
+
+	// Rate value
+	Value decimal.Decimal `gorm:"type:decimal(30,12)"`
+
+	// Rate type: mid, bid or ask
+	RateType string `json:"rate_type"`
+
+	// The date of provider's publication the rate belongs to
+	RateDate string `json:"rate_date"`
+
+	// Provider generated rate time (UTC)
+	ProviderGeneratedTime time.Time `json:"provider_generated_time"`
+
+	// Time since the rate was served (UTC)
+	FetchedTime time.Time `json:"fetched_time"`
+}
+
+// TableName returns MySQL table name
+func (r CurrencyRateSnapshot) TableName() string {
+	return "currency_rate_snapshot"
+}
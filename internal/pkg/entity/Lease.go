@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Lease represents named lock held by one application instance until expiration time
+type Lease struct {
+	// Lease name
+	Name string `gorm:"primaryKey"`
+
+	// Id of instance holding the lease
+	Holder string
+
+	// Lease expiration time (UTC), lease can be taken by other instance after it
+	ExpiresTime *time.Time `json:"expires_time"`
+}
+
+// TableName returns MySQL table name
+func (l Lease) TableName() string {
+	return "lease"
+}
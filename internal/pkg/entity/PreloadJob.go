@@ -0,0 +1,82 @@
+package entity
+
+import "time"
+
+// Preload job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Preload job date statuses
+const (
+	JobDateStatusPending = "pending"
+	JobDateStatusDone    = "done"
+	JobDateStatusFailed  = "failed"
+)
+
+// PreloadJob represents backfill run of provider's historical rates for date range
+type PreloadJob struct {
+	// Id
+	ID uint
+
+	// Provider code
+	Provider string
+
+	// The first date of range
+	StartDate string `json:"start_date"`
+
+	// The last date of range
+	EndDate string `json:"end_date"`
+
+	// Job status: pending, running, completed or failed
+	Status string
+
+	// Job creation time (UTC)
+	CreatedTime time.Time `json:"created_time"`
+
+	// Job start time (UTC)
+	StartedTime *time.Time `json:"started_time"`
+
+	// Job finish time (UTC)
+	FinishedTime *time.Time `json:"finished_time"`
+
+	// Dates of range to preload
+	Dates []PreloadJobDate `gorm:"foreignKey:JobID"`
+}
+
+// TableName returns MySQL table name
+func (j PreloadJob) TableName() string {
+	return "preload_job"
+}
+
+// PreloadJobDate represents state of one date of preload job
+type PreloadJobDate struct {
+	// Id
+	ID uint
+
+	// Preload job id
+	JobID uint `json:"job_id"`
+
+	// Date to preload
+	RateDate string `json:"rate_date"`
+
+	// Date status: pending, done or failed
+	Status string
+
+	// Number of fetch attempts
+	Attempts int
+
+	// The last fetch error
+	Error string
+
+	// The last state change time (UTC)
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+// TableName returns MySQL table name
+func (d PreloadJobDate) TableName() string {
+	return "preload_job_date"
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// QuarantinedRate represents one currency pair exchange rate, which failed ingestion-time sanity checks
+type QuarantinedRate struct {
+	// Id
+	ID uint
+
+	// Provider of this currency exchange rate
+	Provider string
+
+	// Base currency of currency pair
+	BaseCurrency string
+
+	// Quoted currency of currency pair
+	QuotedCurrency string
+
+	// Rate value
+	Value decimal.Decimal `gorm:"type:decimal(30,12)"`
+
+	// Rate type: mid, bid or ask
+	RateType string `json:"rate_type"`
+
+	// The date on which the rates are requested
+	RateDate string `json:"rate_date"`
+
+	// Provider generated rates time (UTC)
+	ProviderGeneratedTime time.Time `json:"provider_generated_time"`
+
+	// Anomalies found in rates of the day
+	Reason string
+
+	// Quarantine time (UTC)
+	CreatedTime time.Time `json:"created_time"`
+}
+
+// TableName returns MySQL table name
+func (r QuarantinedRate) TableName() string {
+	return "currency_rate_quarantine"
+}
@@ -0,0 +1,366 @@
+// Package grpcapi contains gRPC API of application, which mirrors REST API endpoints
+package grpcapi
+
+import (
+	"context"
+	"github.com/netandreus/go-forex-rates/internal/pkg/auth"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/grpcapi/ratespb"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/pipeline"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/stream"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTimeSeriesDays is max number of days in date range of time series request
+const maxTimeSeriesDays = 366
+
+// RatesServer implements gRPC RatesService through the same pipeline as REST API
+type RatesServer struct {
+	ratespb.UnimplementedRatesServiceServer
+
+	config        *model.ApplicationConfig
+	pipeline      *pipeline.Pipeline
+	registry      *provider.Registry
+	availability  *provider.Availability
+	authenticator *auth.Authenticator
+	hub           *stream.Hub
+}
+
+// BuildRatesServer /* *RatesServer
+func BuildRatesServer(
+	config *model.ApplicationConfig,
+	pipeline *pipeline.Pipeline,
+	registry *provider.Registry,
+	availability *provider.Availability,
+	authenticator *auth.Authenticator,
+	hub *stream.Hub) (*RatesServer, error) {
+	return NewRatesServer(config, pipeline, registry, availability, authenticator, hub), nil
+}
+
+// NewRatesServer is the constructor
+func NewRatesServer(
+	config *model.ApplicationConfig,
+	pipeline *pipeline.Pipeline,
+	registry *provider.Registry,
+	availability *provider.Availability,
+	authenticator *auth.Authenticator,
+	hub *stream.Hub) *RatesServer {
+	return &RatesServer{
+		config:        config,
+		pipeline:      pipeline,
+		registry:      registry,
+		availability:  availability,
+		authenticator: authenticator,
+		hub:           hub,
+	}
+}
+
+// Historical returns historical currency rates of date or valid at instant
+func (s *RatesServer) Historical(ctx context.Context, req *ratespb.HistoricalRequest) (*ratespb.RatesReply, error) {
+	if err := s.authenticate(ctx, req.GetProvider()); err != nil {
+		return nil, err
+	}
+	serviceRequest, err := s.buildRequest(util.EndpointHistorical, map[string]string{
+		"provider":  req.GetProvider(),
+		"date":      req.GetDate(),
+		"at":        req.GetAt(),
+		"known_at":  req.GetKnownAt(),
+		"base":      req.GetBase(),
+		"symbols":   strings.Join(req.GetSymbols(), ","),
+		"rate_type": req.GetRateType(),
+		"force":     strconv.FormatBool(req.GetForce()),
+		"resolve":   req.GetResolve(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	serviceResponse, err := s.getHistorical(&serviceRequest)
+	if err != nil {
+		return nil, newStatusError(err)
+	}
+	return newRatesReply(serviceRequest, serviceResponse), nil
+}
+
+// Latest returns latest currency rates
+func (s *RatesServer) Latest(ctx context.Context, req *ratespb.LatestRequest) (*ratespb.RatesReply, error) {
+	if err := s.authenticate(ctx, req.GetProvider()); err != nil {
+		return nil, err
+	}
+	serviceRequest, err := s.buildLatestRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	serviceResponse, _, err := s.pipeline.GetLatest(&serviceRequest)
+	if err != nil {
+		return nil, newStatusError(err)
+	}
+	return newRatesReply(serviceRequest, serviceResponse), nil
+}
+
+// Convert converts amount from one currency to another by historical rate of date, or by latest rate if date is empty
+func (s *RatesServer) Convert(ctx context.Context, req *ratespb.ConvertRequest) (*ratespb.ConvertReply, error) {
+	var (
+		serviceResponse model.RatesResponse
+		endpoint        = util.EndpointLatest
+	)
+	if err := s.authenticate(ctx, req.GetProvider()); err != nil {
+		return nil, err
+	}
+	amount, err := decimal.NewFromString(req.GetAmount())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "unsupported amount. Received: "+req.GetAmount())
+	}
+	if req.GetDate() != "" {
+		endpoint = util.EndpointHistorical
+	}
+	serviceRequest, err := s.buildRequest(endpoint, map[string]string{
+		"provider":  req.GetProvider(),
+		"date":      req.GetDate(),
+		"base":      req.GetFrom(),
+		"symbols":   req.GetTo(),
+		"rate_type": req.GetRateType(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == util.EndpointHistorical {
+		serviceResponse, err = s.getHistorical(&serviceRequest)
+	} else {
+		serviceResponse, _, err = s.pipeline.GetLatest(&serviceRequest)
+	}
+	if err != nil {
+		return nil, newStatusError(err)
+	}
+	rate, ok := serviceResponse.Rates[req.GetTo()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "rate "+req.GetFrom()+"/"+req.GetTo()+" is not found")
+	}
+	prov, err := s.registry.GetProvider(serviceRequest.ProviderCode)
+	if err != nil {
+		return nil, newStatusError(err)
+	}
+	return &ratespb.ConvertReply{
+		Historical:    serviceRequest.Endpoint == util.EndpointHistorical,
+		Date:          serviceRequest.GetRequestedDate().Format(util.DateFormatEu),
+		EffectiveDate: serviceRequest.Date.Format(util.DateFormatEu),
+		Timestamp:     serviceResponse.Timestamp,
+		From:          req.GetFrom(),
+		To:            req.GetTo(),
+		Amount:        amount.String(),
+		Rate:          rate.String(),
+		Result:        amount.Mul(rate).Round(prov.GetPrecision()).String(),
+	}, nil
+}
+
+// TimeSeries returns historical currency rates of every publication day of date range.
+// Range is limited by the latest published date of provider
+func (s *RatesServer) TimeSeries(ctx context.Context, req *ratespb.TimeSeriesRequest) (*ratespb.TimeSeriesReply, error) {
+	serviceRequest, err := s.buildRequest(util.EndpointHistorical, map[string]string{
+		"provider":  req.GetProvider(),
+		"date":      req.GetStartDate(),
+		"base":      req.GetBase(),
+		"symbols":   strings.Join(req.GetSymbols(), ","),
+		"rate_type": req.GetRateType(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := time.ParseInLocation(util.DateFormatEu, req.GetEndDate(), time.UTC)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "unsupported end date. Received: "+req.GetEndDate())
+	}
+	startDate := serviceRequest.Date
+	if endDate.Before(startDate) {
+		return nil, status.Error(codes.InvalidArgument, "end date can not be before start date")
+	}
+	if endDate.Sub(startDate) >= maxTimeSeriesDays*24*time.Hour {
+		return nil, status.Error(codes.InvalidArgument, "date range can not be longer than "+strconv.Itoa(maxTimeSeriesDays)+" days")
+	}
+	prov, err := s.registry.GetProvider(serviceRequest.ProviderCode)
+	if err != nil {
+		return nil, newStatusError(err)
+	}
+
+	reply := &ratespb.TimeSeriesReply{
+		StartDate: startDate.Format(util.DateFormatEu),
+		EndDate:   endDate.Format(util.DateFormatEu),
+		Base:      serviceRequest.BaseCurrency,
+		RateType:  serviceRequest.GetRateType(),
+	}
+	if latestDate := s.availability.GetLatestDate(prov); endDate.After(latestDate) {
+		endDate = latestDate
+	}
+	var dates []time.Time
+	if !endDate.Before(startDate) {
+		dates = prov.GetCapabilities().Calendar.FilterPublicationDays(util.GetDateRangeArr(startDate, endDate))
+	}
+
+	// Every date is counted against monthly quota of access key, as a request of historical rates
+	if err = s.authenticateBatch(ctx, req.GetProvider(), len(dates)); err != nil {
+		return nil, err
+	}
+	for _, date := range dates {
+		if err = ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		dateRequest := serviceRequest
+		dateRequest.Date = date
+		dateRequest.RequestedDate = date
+		serviceResponse, err := s.getHistorical(&dateRequest)
+		if err != nil {
+			return nil, newStatusError(err)
+		}
+		reply.Rates = append(reply.Rates, newRatesReply(dateRequest, serviceResponse))
+	}
+	return reply, nil
+}
+
+// StreamLatest pushes latest currency rates on subscription and every time they are changed, every message contains
+// all subscribed rates. Rates are polled once per subscription group through cache, so subscribers of the same rates
+// share provider requests
+func (s *RatesServer) StreamLatest(req *ratespb.LatestRequest, stream ratespb.RatesService_StreamLatestServer) error {
+	if err := s.authenticate(stream.Context(), req.GetProvider()); err != nil {
+		return err
+	}
+	serviceRequest, err := s.buildLatestRequest(req)
+	if err != nil {
+		return err
+	}
+	subscription, err := s.hub.Subscribe(serviceRequest)
+	if err != nil {
+		return newStatusError(err)
+	}
+	defer subscription.Close()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case update, ok := <-subscription.Updates():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow, subscription is closed")
+			}
+			if err = stream.Send(newRatesReply(update.Request, update.Latest)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// getHistorical returns historical rates through pipeline, base currency requested alone is not looked up
+func (s *RatesServer) getHistorical(serviceRequest *model.RatesRequest) (model.RatesResponse, error) {
+	if serviceRequest.IsEqualCurrencyRequest() {
+		return model.RatesResponse{
+			Rates:     map[string]decimal.Decimal{serviceRequest.BaseCurrency: decimal.NewFromInt(1)},
+			Timestamp: time.Now().Unix(),
+		}, nil
+	}
+	serviceResponse, _, err := s.pipeline.GetHistorical(serviceRequest)
+	return serviceResponse, err
+}
+
+// buildLatestRequest builds rates request of latest rates
+func (s *RatesServer) buildLatestRequest(req *ratespb.LatestRequest) (model.RatesRequest, error) {
+	return s.buildRequest(util.EndpointLatest, map[string]string{
+		"provider":  req.GetProvider(),
+		"base":      req.GetBase(),
+		"symbols":   strings.Join(req.GetSymbols(), ","),
+		"rate_type": req.GetRateType(),
+		"force":     strconv.FormatBool(req.GetForce()),
+	})
+}
+
+// buildRequest builds rates request from params, the same as REST API query and path params
+func (s *RatesServer) buildRequest(endpoint string, params map[string]string) (model.RatesRequest, error) {
+	serviceRequest := model.RatesRequest{}
+	err := serviceRequest.FromParams(func(name string) string {
+		return params[name]
+	}, s.config, endpoint)
+	if err != nil {
+		return serviceRequest, status.Error(codes.InvalidArgument, "error parsing request. "+err.Error())
+	}
+	return serviceRequest, nil
+}
+
+// authenticate validates API access key passed in request metadata, if auth is enabled
+func (s *RatesServer) authenticate(ctx context.Context, providerCode string) error {
+	return s.authenticateBatch(ctx, providerCode, 1)
+}
+
+// authenticateBatch validates access key from request metadata and counts request as passed number of requests
+// against monthly quota
+func (s *RatesServer) authenticateBatch(ctx context.Context, providerCode string, requests int) error {
+	var accessKey string
+	if !s.config.Auth.Enabled {
+		return nil
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(s.config.Auth.Header); len(values) > 0 {
+			accessKey = values[0]
+		}
+	}
+	if _, err := s.authenticator.AuthenticateBatch(accessKey, providerCode, requests); err != nil {
+		return newStatusError(err)
+	}
+	return nil
+}
+
+// newRatesReply converts rates response to gRPC reply, the same as REST API success response
+func newRatesReply(serviceRequest model.RatesRequest, serviceResponse model.RatesResponse) *ratespb.RatesReply {
+	response := model.NewSuccessApiResponse(serviceRequest, serviceResponse)
+	reply := &ratespb.RatesReply{
+		Historical:    response.Historical,
+		Date:          response.Date,
+		EffectiveDate: response.EffectiveDate,
+		Timestamp:     response.Timestamp,
+		Base:          response.Base,
+		RateType:      response.RateType,
+		Rates:         make(map[string]string),
+		Provider:      response.Provider,
+		Stale:         response.Stale,
+		Age:           response.Age,
+	}
+	for currency, rate := range response.Rates {
+		reply.Rates[currency] = rate.String()
+	}
+	if len(response.Sources) > 0 {
+		reply.Sources = make(map[string]*ratespb.Sources)
+		for currency, providers := range response.Sources {
+			reply.Sources[currency] = &ratespb.Sources{Providers: providers}
+		}
+	}
+	return reply
+}
+
+// newStatusError maps typed application error to gRPC status error
+func newStatusError(err error) error {
+	code := codes.Internal
+	switch customerror.GetStatus(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusUnprocessableEntity:
+		code = codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
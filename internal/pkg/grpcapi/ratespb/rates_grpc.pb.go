@@ -0,0 +1,283 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package ratespb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// RatesServiceClient is the client API for RatesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RatesServiceClient interface {
+	// Historical returns historical currency rates of date or valid at instant
+	Historical(ctx context.Context, in *HistoricalRequest, opts ...grpc.CallOption) (*RatesReply, error)
+	// Latest returns latest currency rates
+	Latest(ctx context.Context, in *LatestRequest, opts ...grpc.CallOption) (*RatesReply, error)
+	// Convert converts amount from one currency to another by historical or latest rate
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertReply, error)
+	// TimeSeries returns historical currency rates of every publication day of date range
+	TimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (*TimeSeriesReply, error)
+	// StreamLatest pushes latest currency rates on subscription and every time they are changed
+	StreamLatest(ctx context.Context, in *LatestRequest, opts ...grpc.CallOption) (RatesService_StreamLatestClient, error)
+}
+
+type ratesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRatesServiceClient(cc grpc.ClientConnInterface) RatesServiceClient {
+	return &ratesServiceClient{cc}
+}
+
+func (c *ratesServiceClient) Historical(ctx context.Context, in *HistoricalRequest, opts ...grpc.CallOption) (*RatesReply, error) {
+	out := new(RatesReply)
+	err := c.cc.Invoke(ctx, "/forexrates.v1.RatesService/Historical", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesServiceClient) Latest(ctx context.Context, in *LatestRequest, opts ...grpc.CallOption) (*RatesReply, error) {
+	out := new(RatesReply)
+	err := c.cc.Invoke(ctx, "/forexrates.v1.RatesService/Latest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertReply, error) {
+	out := new(ConvertReply)
+	err := c.cc.Invoke(ctx, "/forexrates.v1.RatesService/Convert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesServiceClient) TimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (*TimeSeriesReply, error) {
+	out := new(TimeSeriesReply)
+	err := c.cc.Invoke(ctx, "/forexrates.v1.RatesService/TimeSeries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratesServiceClient) StreamLatest(ctx context.Context, in *LatestRequest, opts ...grpc.CallOption) (RatesService_StreamLatestClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RatesService_ServiceDesc.Streams[0], "/forexrates.v1.RatesService/StreamLatest", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ratesServiceStreamLatestClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RatesService_StreamLatestClient interface {
+	Recv() (*RatesReply, error)
+	grpc.ClientStream
+}
+
+type ratesServiceStreamLatestClient struct {
+	grpc.ClientStream
+}
+
+func (x *ratesServiceStreamLatestClient) Recv() (*RatesReply, error) {
+	m := new(RatesReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RatesServiceServer is the server API for RatesService service.
+// All implementations must embed UnimplementedRatesServiceServer
+// for forward compatibility
+type RatesServiceServer interface {
+	// Historical returns historical currency rates of date or valid at instant
+	Historical(context.Context, *HistoricalRequest) (*RatesReply, error)
+	// Latest returns latest currency rates
+	Latest(context.Context, *LatestRequest) (*RatesReply, error)
+	// Convert converts amount from one currency to another by historical or latest rate
+	Convert(context.Context, *ConvertRequest) (*ConvertReply, error)
+	// TimeSeries returns historical currency rates of every publication day of date range
+	TimeSeries(context.Context, *TimeSeriesRequest) (*TimeSeriesReply, error)
+	// StreamLatest pushes latest currency rates on subscription and every time they are changed
+	StreamLatest(*LatestRequest, RatesService_StreamLatestServer) error
+	mustEmbedUnimplementedRatesServiceServer()
+}
+
+// UnimplementedRatesServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRatesServiceServer struct {
+}
+
+func (UnimplementedRatesServiceServer) Historical(context.Context, *HistoricalRequest) (*RatesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Historical not implemented")
+}
+func (UnimplementedRatesServiceServer) Latest(context.Context, *LatestRequest) (*RatesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Latest not implemented")
+}
+func (UnimplementedRatesServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedRatesServiceServer) TimeSeries(context.Context, *TimeSeriesRequest) (*TimeSeriesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TimeSeries not implemented")
+}
+func (UnimplementedRatesServiceServer) StreamLatest(*LatestRequest, RatesService_StreamLatestServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLatest not implemented")
+}
+func (UnimplementedRatesServiceServer) mustEmbedUnimplementedRatesServiceServer() {}
+
+// UnsafeRatesServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RatesServiceServer will
+// result in compilation errors.
+type UnsafeRatesServiceServer interface {
+	mustEmbedUnimplementedRatesServiceServer()
+}
+
+func RegisterRatesServiceServer(s grpc.ServiceRegistrar, srv RatesServiceServer) {
+	s.RegisterService(&RatesService_ServiceDesc, srv)
+}
+
+func _RatesService_Historical_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoricalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServiceServer).Historical(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/forexrates.v1.RatesService/Historical",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServiceServer).Historical(ctx, req.(*HistoricalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatesService_Latest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LatestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServiceServer).Latest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/forexrates.v1.RatesService/Latest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServiceServer).Latest(ctx, req.(*LatestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatesService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/forexrates.v1.RatesService/Convert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatesService_TimeSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimeSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatesServiceServer).TimeSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/forexrates.v1.RatesService/TimeSeries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatesServiceServer).TimeSeries(ctx, req.(*TimeSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatesService_StreamLatest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LatestRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RatesServiceServer).StreamLatest(m, &ratesServiceStreamLatestServer{stream})
+}
+
+type RatesService_StreamLatestServer interface {
+	Send(*RatesReply) error
+	grpc.ServerStream
+}
+
+type ratesServiceStreamLatestServer struct {
+	grpc.ServerStream
+}
+
+func (x *ratesServiceStreamLatestServer) Send(m *RatesReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RatesService_ServiceDesc is the grpc.ServiceDesc for RatesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RatesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "forexrates.v1.RatesService",
+	HandlerType: (*RatesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Historical",
+			Handler:    _RatesService_Historical_Handler,
+		},
+		{
+			MethodName: "Latest",
+			Handler:    _RatesService_Latest_Handler,
+		},
+		{
+			MethodName: "Convert",
+			Handler:    _RatesService_Convert_Handler,
+		},
+		{
+			MethodName: "TimeSeries",
+			Handler:    _RatesService_TimeSeries_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLatest",
+			Handler:       _RatesService_StreamLatest_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rates.proto",
+}
@@ -0,0 +1,149 @@
+// Package leader implements election of instance, which runs preload, among application replicas
+package leader
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LeaseName is name of lease held by leader
+const LeaseName = "preload"
+
+// DefaultTTL is lease TTL in seconds, if it is not configured
+const DefaultTTL = 30
+
+// Elector holds preload lease in L2 database. Only instance holding the lease (leader) runs cron preload
+// and preload jobs. Leader prolongs lease every third of TTL, if leader dies lease expires and is taken by other instance.
+// If leader election is disabled, instance is always leader
+type Elector struct {
+	config *model.ApplicationConfig
+	leases *repository.LeaseRepository
+	holder string
+
+	mu        sync.RWMutex
+	leader    bool
+	onElected []func()
+	stop      chan struct{}
+}
+
+// BuildElector /* *Elector
+func BuildElector(config *model.ApplicationConfig, leases *repository.LeaseRepository) (*Elector, error) {
+	return NewElector(config, leases), nil
+}
+
+// NewElector constructor
+func NewElector(config *model.ApplicationConfig, leases *repository.LeaseRepository) *Elector {
+	hostname, _ := os.Hostname()
+	return &Elector{
+		config: config,
+		leases: leases,
+		holder: hostname + "-" + strconv.Itoa(os.Getpid()),
+		stop:   make(chan struct{}),
+	}
+}
+
+// OnElected adds callback, which is called when instance becomes leader
+func (e *Elector) OnElected(fn func()) {
+	e.onElected = append(e.onElected, fn)
+}
+
+// IsLeader returns true if instance holds the lease
+func (e *Elector) IsLeader() bool {
+	if !e.config.Leader.Enabled {
+		return true
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// GetHolder returns id of this instance
+func (e *Elector) GetHolder() string {
+	return e.holder
+}
+
+// Start tries to take the lease and keeps trying or prolonging it in background
+func (e *Elector) Start() {
+	if !e.config.Leader.Enabled {
+		e.elected()
+		return
+	}
+	e.campaign()
+	go func() {
+		ticker := time.NewTicker(e.getInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				e.campaign()
+			}
+		}
+	}()
+}
+
+// Stop stops election and releases the lease, so other instance takes it without waiting for expiration
+func (e *Elector) Stop() {
+	if !e.config.Leader.Enabled {
+		return
+	}
+	close(e.stop)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leader {
+		e.leader = false
+		if err := e.leases.Release(LeaseName, e.holder); err != nil {
+			logger.LogError("Preload lease is not released. "+err.Error(), "LEADER")
+		}
+	}
+}
+
+// campaign takes or prolongs the lease and handles change of leadership
+func (e *Elector) campaign() {
+	acquired, err := e.leases.Acquire(LeaseName, e.holder, e.GetTTL())
+	if err != nil {
+		logger.LogError("Preload lease is not acquired. "+err.Error(), "LEADER")
+	}
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = acquired
+	e.mu.Unlock()
+
+	switch {
+	case acquired && !wasLeader:
+		logger.LogSuccess("Instance "+e.holder+" is elected as preload leader", "LEADER")
+		go e.elected()
+	case !acquired && wasLeader:
+		logger.LogWarning("Instance "+e.holder+" lost preload leadership", "LEADER")
+	}
+}
+
+// elected calls callbacks of leader election
+func (e *Elector) elected() {
+	for _, fn := range e.onElected {
+		fn()
+	}
+}
+
+// GetTTL returns lease TTL in seconds
+func (e *Elector) GetTTL() int {
+	if e.config.Leader.TTL <= 0 {
+		return DefaultTTL
+	}
+	return e.config.Leader.TTL
+}
+
+// getInterval returns interval of lease prolongation
+func (e *Elector) getInterval() time.Duration {
+	interval := time.Duration(e.GetTTL()) * time.Second / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
@@ -12,6 +12,9 @@ type ApplicationConfig struct {
 
 		// Delay between requests of provider API
 		RandomDelay int `yaml:"random_delay" env:"COLLECTOR_DELAY" env-default:1`
+
+		// Max number of fetch attempts of one date
+		MaxAttempts int `yaml:"max_attempts" env:"COLLECTOR_MAX_ATTEMPTS" env-default:"3"`
 	} `yaml:"collector"`
 
 	// Level-1 cache settings (go-cache)
@@ -41,6 +44,67 @@ type ApplicationConfig struct {
 		Database string `yaml:"database" env:"L2_DATABASE" env-default:"go_forex_rates"`
 	} `yaml:"l2_cache"`
 
+	// Latest rates settings
+	Latest struct {
+		// Seconds after L1 cache expiration, while last-known-good rates are served and refreshed in background (0 - disabled)
+		StaleWhileRevalidate int `yaml:"stale_while_revalidate" env:"LATEST_STALE_WHILE_REVALIDATE" env-default:"0"`
+
+		// Max age in seconds of last-known-good rates, which are served if provider fails (0 - disabled)
+		StaleIfError int `yaml:"stale_if_error" env:"LATEST_STALE_IF_ERROR" env-default:"3600"`
+
+		// Persist every distinct latest rate fetched from providers to snapshots table
+		Snapshots bool `yaml:"snapshots" env:"LATEST_SNAPSHOTS" env-default:"false"`
+	} `yaml:"latest"`
+
+	// Gaps repair settings
+	GapRepair struct {
+		// Cron expression of scan and repair of gaps in stored historical rates (empty - disabled)
+		Schedule string `yaml:"schedule" env:"GAP_REPAIR_SCHEDULE" env-default:""`
+	} `yaml:"gap_repair"`
+
+	// Leader election settings of multi-replica deployment
+	Leader struct {
+		// Run preload on single instance holding lease in L2 database (false - every instance runs preload)
+		Enabled bool `yaml:"enabled" env:"LEADER_ENABLED" env-default:"false"`
+
+		// Lease TTL in seconds, other instance takes the lease after leader has not prolonged it for TTL
+		TTL int `yaml:"ttl" env:"LEADER_TTL" env-default:"30"`
+	} `yaml:"leader"`
+
+	// API access keys settings
+	Auth struct {
+		// Require API access key (false - API is open)
+		Enabled bool `yaml:"enabled" env:"AUTH_ENABLED" env-default:"false"`
+
+		// Request header with API access key, access_key query parameter is also accepted
+		Header string `yaml:"header" env:"AUTH_HEADER" env-default:"X-Api-Key"`
+
+		// API access keys, keys are also loaded from database
+		Keys []ApiKeyConfig `yaml:"keys"`
+	} `yaml:"auth"`
+
+	// gRPC server settings
+	Grpc struct {
+		// Start gRPC server alongside REST HTTP server
+		Enabled bool `yaml:"enabled" env:"GRPC_ENABLED" env-default:"false"`
+
+		// Listen port
+		Port int `yaml:"port" env:"GRPC_PORT" env-default:"9091"`
+
+		// Deprecated: use Stream.Interval. Interval in seconds of latest rates polling of streaming subscription,
+		// used if stream.interval is not set
+		StreamInterval int `yaml:"stream_interval" env:"GRPC_STREAM_INTERVAL" env-default:"0"`
+	} `yaml:"grpc"`
+
+	// Streaming of latest rates changes settings (Server-Sent Events and gRPC)
+	Stream struct {
+		// Interval in seconds of latest rates polling of every subscription group (0 - L1 cache expiration)
+		Interval int `yaml:"interval" env:"STREAM_INTERVAL" env-default:"0"`
+
+		// Interval in seconds of keep-alive comments of Server-Sent Events stream
+		Heartbeat int `yaml:"heartbeat" env:"STREAM_HEARTBEAT" env-default:"15"`
+	} `yaml:"stream"`
+
 	// Providers settings
 	Providers map[string]ProviderConfig
 }
@@ -65,12 +129,99 @@ type ProviderConfig struct {
 	// Access token for provider's API
 	APIKey string `yaml:"api_key" env-default:""`
 
+	// Provider's API request timeout in seconds (30 by default)
+	Timeout int `yaml:"timeout"`
+
 	// List of currencies, supporting by provider
 	SupportedCurrencies []string `yaml:"supported_currencies"`
 
+	// Number of decimal places of provider's rates (6 by default, up to 12)
+	Precision int32 `yaml:"precision"`
+
 	// Enable or disable preload historical rates to L2 cache (database)
 	HistoricalPreload bool `yaml:"historical_preload"`
 
+	// Cron expression of historical rates preload, UTC (daily at rates_generated_time by default)
+	Schedule string `yaml:"schedule"`
+
+	// Polling of provider after scheduled preload until rates of the date are published
+	Retry RetryConfig `yaml:"retry"`
+
+	// Number of days up to the last stored date re-fetched by scheduled preload to detect corrected rates (0 - disabled)
+	RefetchDays int `yaml:"refetch_days"`
+
 	// Start date for preload historical currency rates
 	HistoricalStartDate string `yaml:"historical_start_date"`
+
+	// Days of week when provider does not publish rates (Saturday, Sunday etc.)
+	Weekend []string `yaml:"weekend"`
+
+	// Weekend changes with effective dates, weekend is used before the first of them
+	WeekendRules []WeekendRuleConfig `yaml:"weekend_rules"`
+
+	// Bank holidays in provider's location when provider does not publish rates (format YYYY-MM-DD)
+	Holidays []string `yaml:"holidays"`
+
+	// Codes of underlying providers in priority order (for aggregating providers only)
+	Providers []string `yaml:"providers"`
+
+	// Max relative deviation of source rate from median (for consensus provider only)
+	Tolerance float64 `yaml:"tolerance" env-default:"0.001"`
+
+	// Ingestion-time sanity checks of fetched rates
+	Anomaly AnomalyConfig `yaml:"anomaly"`
+}
+
+// ApiKeyConfig is API access key with its limits
+type ApiKeyConfig struct {
+	// Unique name of key owner, usage is counted by name
+	Name string `yaml:"name"`
+
+	// Access key
+	Key string `yaml:"key"`
+
+	// Max number of requests per minute (0 - unlimited)
+	RateLimit int `yaml:"rate_limit"`
+
+	// Max number of requests per calendar month, UTC (0 - unlimited)
+	MonthlyQuota int `yaml:"monthly_quota"`
+
+	// Codes of allowed providers (empty - all providers)
+	Providers []string `yaml:"providers"`
+
+	// Allow access to Admin API
+	Admin bool `yaml:"admin"`
+}
+
+// WeekendRuleConfig is weekend of provider's location effective since date
+type WeekendRuleConfig struct {
+	// The first date of rule (format YYYY-MM-DD)
+	Since string `yaml:"since"`
+
+	// Days of week when provider does not publish rates since the date
+	Weekend []string `yaml:"weekend"`
+}
+
+// RetryConfig is settings of polling of provider until rates are published
+type RetryConfig struct {
+	// Interval in minutes between preload attempts (0 - retry disabled)
+	Interval int `yaml:"interval"`
+
+	// Minutes after scheduled preload, when polling stops and alert is logged (0 - retry disabled)
+	Deadline int `yaml:"deadline"`
+}
+
+// AnomalyConfig is settings of ingestion-time sanity checks of provider's rates
+type AnomalyConfig struct {
+	// Max relative day-over-day change of rate (0 - check disabled)
+	MaxDailyChange float64 `yaml:"max_daily_change"`
+
+	// Max relative day-over-day change of rate per quoted currency, overrides max_daily_change
+	MaxDailyChanges map[string]float64 `yaml:"max_daily_changes"`
+
+	// Max relative deviation of direct rate multiplied by reverse one from 1 (0.0001 by default)
+	ReciprocalTolerance float64 `yaml:"reciprocal_tolerance"`
+
+	// Max number of supported currencies missing in provider's response (0 - check disabled)
+	MaxMissingCurrencies int `yaml:"max_missing_currencies"`
 }
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+)
+
+// AverageRate is statistics of currency pair rates over period
+type AverageRate struct {
+	// Mean arithmetic mean of rates.
+	Mean decimal.Decimal `json:"mean" swaggertype:"number"`
+
+	// Min the minimal rate.
+	Min decimal.Decimal `json:"min" swaggertype:"number"`
+
+	// Max the maximal rate.
+	Max decimal.Decimal `json:"max" swaggertype:"number"`
+
+	// Count the number of observations (stored daily rates) used.
+	Count int `json:"count"`
+}
+
+// AverageApiResponse represents success response of average rates API
+type AverageApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Period the requested period: YYYY-MM, YYYY-Qn or YYYY.
+	Period string `json:"period"`
+
+	// StartDate the first date of period.
+	StartDate string `json:"start_date"`
+
+	// EndDate the last date of period.
+	EndDate string `json:"end_date"`
+
+	// Closed true if period is over and its averages will not change.
+	Closed bool `json:"closed"`
+
+	// PublicationDays true if only rates of provider's publication days were used.
+	PublicationDays bool `json:"publication_days"`
+
+	// Base the three-letter currency code of the base currency used for this request.
+	Base string `json:"base"`
+
+	// RateType the side of rates: mid, bid or ask.
+	RateType string `json:"rate_type"`
+
+	// Rates statistics of rates over period for the currencies you have requested.
+	Rates map[string]AverageRate `json:"rates"`
+
+	// format of rates: number or string
+	format string
+}
+
+// NewAverageApiResponse constructor
+func NewAverageApiResponse(averageRequest AverageRequest, rates map[string]AverageRate, closed bool) *AverageApiResponse {
+	return &AverageApiResponse{
+		Success:         true,
+		Period:          averageRequest.Period.Name,
+		StartDate:       averageRequest.Period.Start.Format(util.DateFormatEu),
+		EndDate:         averageRequest.Period.End.Format(util.DateFormatEu),
+		Closed:          closed,
+		PublicationDays: averageRequest.PublicationDays,
+		Base:            averageRequest.BaseCurrency,
+		RateType:        averageRequest.RateType,
+		Rates:           rates,
+		format:          averageRequest.Format,
+	}
+}
+
+// MarshalJSON encodes rates as JSON strings if string format requested, as JSON numbers otherwise
+func (a AverageApiResponse) MarshalJSON() ([]byte, error) {
+	type response AverageApiResponse
+	type averageRate struct {
+		Mean  string `json:"mean"`
+		Min   string `json:"min"`
+		Max   string `json:"max"`
+		Count int    `json:"count"`
+	}
+	if a.format != util.FormatString {
+		return json.Marshal(response(a))
+	}
+	rates := make(map[string]averageRate)
+	for currency, rate := range a.Rates {
+		rates[currency] = averageRate{
+			Mean:  rate.Mean.String(),
+			Min:   rate.Min.String(),
+			Max:   rate.Max.String(),
+			Count: rate.Count,
+		}
+	}
+	return json.Marshal(struct {
+		response
+		Rates map[string]averageRate `json:"rates"`
+	}{response(a), rates})
+}
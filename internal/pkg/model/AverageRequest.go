@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"strconv"
+	"strings"
+)
+
+// AverageRequest is request of average rates over accounting period
+type AverageRequest struct {
+	// Requested provider code
+	ProviderCode string
+
+	// Requested accounting period
+	Period Period
+
+	// Requested base currency
+	BaseCurrency string
+
+	// Requested quoted currencies
+	Symbols []string
+
+	// Requested rate type (side): mid, bid or ask
+	RateType string
+
+	// If true - average only rates of provider's publication days (without weekends and bank holidays)
+	PublicationDays bool
+
+	// Format of rates in API response: number (default) or string
+	Format string
+}
+
+// FromGinContext fills with data from HTTP Request
+func (r *AverageRequest) FromGinContext(c *gin.Context) error {
+	var err error
+
+	// Provider code
+	r.ProviderCode = c.Param("provider")
+
+	// Period check
+	if r.Period, err = ParsePeriod(c.Param("period")); err != nil {
+		return err
+	}
+
+	// Base currency check
+	baseCurrency := c.Query("base")
+	if len(baseCurrency) != 3 {
+		return errors.New("unsupported base currency. Received: " + baseCurrency)
+	}
+	r.BaseCurrency = baseCurrency
+
+	// Symbols check
+	symbols := util.UniqueStringSlice(strings.Split(c.Query("symbols"), ","))
+	for _, symbol := range symbols {
+		if len(symbol) != 3 {
+			return errors.New("unsupported quoted currency. Received: " + symbol)
+		}
+	}
+	r.Symbols = symbols
+
+	// Rate type check
+	rateType := c.DefaultQuery("rate_type", util.RateTypeMid)
+	if rateType != util.RateTypeMid && rateType != util.RateTypeBid && rateType != util.RateTypeAsk {
+		return errors.New("unsupported rate type. Allows only(mid, bid, ask). Received: " + rateType)
+	}
+	r.RateType = rateType
+
+	// Publication days check
+	r.PublicationDays, _ = strconv.ParseBool(c.Query("publication_days"))
+
+	// Format check
+	format := c.DefaultQuery("format", util.FormatNumber)
+	if format != util.FormatNumber && format != util.FormatString {
+		return errors.New("unsupported format. Allows only(number, string). Received: " + format)
+	}
+	r.Format = format
+	return nil
+}
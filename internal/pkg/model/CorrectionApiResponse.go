@@ -0,0 +1,100 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/shopspring/decimal"
+)
+
+// CorrectRatesRequest is request of manual correction of stored historical rates
+type CorrectRatesRequest struct {
+	// Base the base currency.
+	Base string `json:"base" binding:"required"`
+
+	// RateType the rate type: mid (default), bid or ask.
+	RateType string `json:"rate_type"`
+
+	// Rates the corrected rates of quoted currencies.
+	Rates map[string]decimal.Decimal `json:"rates" binding:"required" swaggertype:"object,number"`
+}
+
+// CorrectionApiResponse represents response of cached rates invalidation and correction admin API
+type CorrectionApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Provider the code of provider.
+	Provider string `json:"provider"`
+
+	// Action the performed action: purge_cache, delete_rates, overwrite_rates, refetch_rates, release_quarantine
+	// or discard_quarantine.
+	Action string `json:"action"`
+
+	// Affected the number of affected cache entries or pairs.
+	Affected int64 `json:"affected"`
+}
+
+// AuditRecord is change of cached rates made via Admin API
+type AuditRecord struct {
+	// ID the record id.
+	ID uint `json:"id"`
+
+	// Actor the name of API access key owner, who made the change.
+	Actor string `json:"actor"`
+
+	// Action the performed action.
+	Action string `json:"action"`
+
+	// Provider the code of provider.
+	Provider string `json:"provider"`
+
+	// Params the action parameters.
+	Params json.RawMessage `json:"params" swaggertype:"object"`
+
+	// Status the change status: pending (in progress or interrupted), done or failed.
+	Status string `json:"status"`
+
+	// Affected the number of affected cache entries or pairs.
+	Affected int64 `json:"affected"`
+
+	// Error the error message, if action failed.
+	Error string `json:"error,omitempty"`
+
+	// CreatedTime the action time (UNIX time stamp).
+	CreatedTime int64 `json:"created_time"`
+}
+
+// AuditApiResponse represents response of audit log admin API
+type AuditApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Records the latest audit log records.
+	Records []AuditRecord `json:"records"`
+}
+
+// QuarantinedDay is rates of provider for date, which failed ingestion-time sanity checks
+type QuarantinedDay struct {
+	// Date the date of quarantined rates.
+	Date string `json:"date"`
+
+	// Reason the anomalies found in rates of the day.
+	Reason string `json:"reason"`
+
+	// Pairs the number of quarantined currency pairs.
+	Pairs int `json:"pairs"`
+
+	// CreatedTime the quarantine time (UNIX time stamp).
+	CreatedTime int64 `json:"created_time"`
+}
+
+// QuarantineApiResponse represents response of quarantined rates admin API
+type QuarantineApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Provider the code of provider.
+	Provider string `json:"provider"`
+
+	// Days the quarantined days in ascending order.
+	Days []QuarantinedDay `json:"days"`
+}
@@ -1,12 +1,20 @@
 package model
 
+import "github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+
 // ApiError represents error in API
 type ApiError struct {
-	// Error code
+	// Error code (HTTP status)
 	Code int `json:"code"`
 
+	// Stable machine-readable error code
+	Type string `json:"type"`
+
 	// Error message
 	Info string `json:"info"`
+
+	// Can request be retried later (provider or database outage)
+	Retryable bool `json:"retryable"`
 }
 
 // FailedApiResponse response, when error occurred
@@ -26,6 +34,19 @@ func NewFailedApiResponse(code int, info string) *FailedApiResponse {
 	}
 }
 
+// NewFailedApiResponseFromError constructor, maps typed error to HTTP status and machine-readable code
+func NewFailedApiResponseFromError(err error) *FailedApiResponse {
+	return &FailedApiResponse{
+		Success: false,
+		Error: ApiError{
+			Code:      customerror.GetStatus(err),
+			Type:      customerror.GetCode(err),
+			Info:      err.Error(),
+			Retryable: customerror.IsRetryable(err),
+		},
+	}
+}
+
 // GetSuccess returns Success value
 func (a *FailedApiResponse) GetSuccess() bool {
 	return a.Success
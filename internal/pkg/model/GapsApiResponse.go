@@ -0,0 +1,34 @@
+package model
+
+// GapInfo is publication day with missing or partially stored historical rates
+type GapInfo struct {
+	// Date publication day (format YYYY-MM-DD).
+	Date string `json:"date"`
+
+	// Stored the number of stored pairs.
+	Stored int `json:"stored"`
+
+	// Expected the number of expected pairs, 0 if unknown.
+	Expected int `json:"expected"`
+}
+
+// GapsApiResponse represents response of gaps admin API
+type GapsApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Provider the code of provider.
+	Provider string `json:"provider"`
+
+	// StartDate the first scanned date.
+	StartDate string `json:"start_date"`
+
+	// EndDate the last scanned date.
+	EndDate string `json:"end_date"`
+
+	// JobID the id of preload job started to repair gaps, 0 if repair is not started.
+	JobID uint `json:"job_id,omitempty"`
+
+	// Gaps the days with missing or partially stored rates.
+	Gaps []GapInfo `json:"gaps"`
+}
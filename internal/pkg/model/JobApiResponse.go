@@ -0,0 +1,79 @@
+package model
+
+// JobDateInfo is state of one date of preload job
+type JobDateInfo struct {
+	// Date to preload (format YYYY-MM-DD).
+	Date string `json:"date"`
+
+	// Status the date status: pending, done or failed.
+	Status string `json:"status"`
+
+	// Attempts the number of fetch attempts.
+	Attempts int `json:"attempts"`
+
+	// Error the last fetch error.
+	Error string `json:"error,omitempty"`
+}
+
+// JobInfo is preload job with its progress
+type JobInfo struct {
+	// ID the job id.
+	ID uint `json:"id"`
+
+	// Provider the code of provider.
+	Provider string `json:"provider"`
+
+	// StartDate the first date of range.
+	StartDate string `json:"start_date"`
+
+	// EndDate the last date of range.
+	EndDate string `json:"end_date"`
+
+	// Status the job status: pending, running, completed or failed.
+	Status string `json:"status"`
+
+	// Progress the number of dates per status.
+	Progress map[string]int `json:"progress"`
+
+	// CreatedTime the job creation time (UNIX time stamp).
+	CreatedTime int64 `json:"created_time"`
+
+	// StartedTime the job start time (UNIX time stamp), 0 if not started.
+	StartedTime int64 `json:"started_time"`
+
+	// FinishedTime the job finish time (UNIX time stamp), 0 if not finished.
+	FinishedTime int64 `json:"finished_time"`
+
+	// Dates the state of every date of job.
+	Dates []JobDateInfo `json:"dates,omitempty"`
+}
+
+// JobApiResponse represents response of preload job admin API
+type JobApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Job the preload job.
+	Job JobInfo `json:"job"`
+}
+
+// JobsApiResponse represents response of preload jobs list admin API
+type JobsApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Jobs the latest preload jobs.
+	Jobs []JobInfo `json:"jobs"`
+}
+
+// CreateJobRequest is request of new preload job
+type CreateJobRequest struct {
+	// Provider the code of provider.
+	Provider string `json:"provider" binding:"required"`
+
+	// StartDate the first date of range (format YYYY-MM-DD).
+	StartDate string `json:"start_date" binding:"required"`
+
+	// EndDate the last date of range (format YYYY-MM-DD).
+	EndDate string `json:"end_date" binding:"required"`
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Period types
+const (
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+var (
+	monthPeriodRegexp   = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	quarterPeriodRegexp = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+	yearPeriodRegexp    = regexp.MustCompile(`^(\d{4})$`)
+)
+
+// Period is accounting period: month (2021-08), quarter (2021-Q3) or year (2021)
+type Period struct {
+	// Period name as it was requested
+	Name string
+
+	// Period type: month, quarter or year
+	Type string
+
+	// The first date of period
+	Start time.Time
+
+	// The last date of period
+	End time.Time
+}
+
+// ParsePeriod parses period name: YYYY-MM, YYYY-Qn or YYYY
+func ParsePeriod(name string) (Period, error) {
+	period := Period{Name: name}
+	if matches := monthPeriodRegexp.FindStringSubmatch(name); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		month, _ := strconv.Atoi(matches[2])
+		if month < 1 || month > 12 {
+			return period, errors.New("unsupported period month. Received: " + name)
+		}
+		period.Type = PeriodMonth
+		period.Start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		period.End = period.Start.AddDate(0, 1, -1)
+		return period, nil
+	}
+	if matches := quarterPeriodRegexp.FindStringSubmatch(name); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		quarter, _ := strconv.Atoi(matches[2])
+		period.Type = PeriodQuarter
+		period.Start = time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		period.End = period.Start.AddDate(0, 3, -1)
+		return period, nil
+	}
+	if matches := yearPeriodRegexp.FindStringSubmatch(name); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		period.Type = PeriodYear
+		period.Start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		period.End = period.Start.AddDate(1, 0, -1)
+		return period, nil
+	}
+	return period, errors.New("unsupported period format. Allows only(YYYY-MM, YYYY-Qn, YYYY). Received: " + name)
+}
+
+// GetPeriodNames returns names of all months, quarters and years containing dates of range
+func GetPeriodNames(start time.Time, end time.Time) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(end); month = month.AddDate(0, 1, 0) {
+		year := strconv.Itoa(month.Year())
+		quarter := year + "-Q" + strconv.Itoa((int(month.Month())-1)/3+1)
+		for _, name := range []string{month.Format("2006-01"), quarter, year} {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"time"
+)
+
+// RatesFilter selects cached rates of provider by date range and currency pair. Empty fields match any value
+type RatesFilter struct {
+	// Provider code
+	ProviderCode string `json:"provider"`
+
+	// The first date of range
+	StartDate time.Time `json:"-"`
+
+	// The last date of range
+	EndDate time.Time `json:"-"`
+
+	// Base currency of currency pair
+	BaseCurrency string `json:"base,omitempty"`
+
+	// Quoted currency of currency pair
+	QuotedCurrency string `json:"symbol,omitempty"`
+
+	// Rate type: mid, bid or ask
+	RateType string `json:"rate_type,omitempty"`
+}
+
+// HasDates returns true if date range is set
+func (f RatesFilter) HasDates() bool {
+	return !f.StartDate.IsZero() || !f.EndDate.IsZero()
+}
+
+// MatchRequest returns true if rates of cache key request are selected by filter.
+// Latest rates are selected only by filter without date range
+func (f RatesFilter) MatchRequest(r RatesRequest) bool {
+	if r.ProviderCode != f.ProviderCode {
+		return false
+	}
+	if f.HasDates() {
+		if r.Endpoint != util.EndpointHistorical {
+			return false
+		}
+		if !f.StartDate.IsZero() && r.Date.Before(f.StartDate) {
+			return false
+		}
+		if !f.EndDate.IsZero() && r.Date.After(f.EndDate) {
+			return false
+		}
+	}
+	if f.BaseCurrency != "" && r.BaseCurrency != f.BaseCurrency {
+		return false
+	}
+	if f.QuotedCurrency != "" && !util.Contains(r.Symbols, f.QuotedCurrency) {
+		return false
+	}
+	if f.RateType != "" && r.GetRateType() != f.RateType {
+		return false
+	}
+	return true
+}
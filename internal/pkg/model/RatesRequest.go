@@ -24,15 +24,33 @@ type RatesRequest struct {
 	// Requested currency rates date
 	Date time.Time `json:"date"`
 
+	// Date passed by client, before resolving it to provider's publication day
+	RequestedDate time.Time `json:"-"`
+
+	// Instant, the rates are requested at. Historical rates date is resolved from it by provider's publication schedule
+	At time.Time `json:"-"`
+
+	// Instant, the historical rates are requested as known at. Rates corrected later are served as prior revisions
+	KnownAt time.Time `json:"-"`
+
+	// How to resolve non-publication date: "previous" - to the previous publication day, empty - do not resolve
+	Resolve string `json:"resolve,omitempty"`
+
 	// Requested base currency
 	BaseCurrency string `json:"base_currency"`
 
 	// Requested quoted currencies
 	Symbols []string `json:"symbols"`
 
+	// Requested rate type (side): mid, bid or ask
+	RateType string `json:"rate_type"`
+
 	// If true - do not use any type of cache, makes provider API request this case
 	Force bool
 
+	// Format of rates in API response: number (default) or string
+	Format string `json:"-"`
+
 	// Is this request build from another
 	IsForwarded bool
 }
@@ -53,6 +71,17 @@ func (r *RatesRequest) FromString(str string) error {
 
 // FromGinContext fills with data from HTTP Request
 func (r *RatesRequest) FromGinContext(c *gin.Context, config *ApplicationConfig, endpoint string) error {
+	return r.FromParams(func(name string) string {
+		if name == "provider" || name == "date" {
+			return c.Param(name)
+		}
+		return c.Query(name)
+	}, config, endpoint)
+}
+
+// FromParams fills with data from request params, passed param function returns param value by its name
+// (the same as HTTP API query and path params), empty string if param is missing
+func (r *RatesRequest) FromParams(param func(name string) string, config *ApplicationConfig, endpoint string) error {
 	var (
 		err   error
 		date  time.Time
@@ -64,41 +93,112 @@ func (r *RatesRequest) FromGinContext(c *gin.Context, config *ApplicationConfig,
 	}
 	r.Endpoint = endpoint
 
-	// Date check
-	if endpoint == util.EndpointHistorical {
-		date, err = time.ParseInLocation(util.DateFormatEu, c.Param("date"), time.UTC)
+	// Instant check
+	if atStr := param("at"); atStr != "" {
+		at, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			return errors.New("unsupported instant format, RFC 3339 (2021-08-02T14:30:00Z) is expected. Received: " + atStr)
+		}
+		r.At = at
+	}
+
+	// As known at instant check
+	if knownAtStr := param("known_at"); knownAtStr != "" {
+		if endpoint != util.EndpointHistorical {
+			return errors.New("rates as known at instant (known_at) can be requested for historical endpoint only")
+		}
+		knownAt, err := time.Parse(time.RFC3339, knownAtStr)
 		if err != nil {
-			return err
+			return errors.New("unsupported known_at format, RFC 3339 (2021-08-02T14:30:00Z) is expected. Received: " + knownAtStr)
+		}
+		r.KnownAt = knownAt
+	}
+
+	// Date check. Historical rates can be requested at instant instead of date
+	if endpoint == util.EndpointHistorical {
+		dateStr := param("date")
+		if dateStr != "" && !r.At.IsZero() {
+			return errors.New("date and instant (at) can not be requested together")
+		}
+		if dateStr != "" || r.At.IsZero() {
+			date, err = time.ParseInLocation(util.DateFormatEu, dateStr, time.UTC)
+			if err != nil {
+				return err
+			}
+			r.Date = date
+			r.RequestedDate = date
 		}
-		r.Date = date
 	}
 
+	// Resolve mode check
+	resolve := param("resolve")
+	if resolve != "" && resolve != util.ResolvePrevious {
+		return errors.New("unsupported resolve mode. Allows only(previous). Received: " + resolve)
+	}
+	r.Resolve = resolve
+
+	// Format check
+	format := param("format")
+	if format == "" {
+		format = util.FormatNumber
+	}
+	if format != util.FormatNumber && format != util.FormatString {
+		return errors.New("unsupported format. Allows only(number, string). Received: " + format)
+	}
+	r.Format = format
+
 	// Force check
-	force, _ = strconv.ParseBool(c.Query("force"))
+	force, _ = strconv.ParseBool(param("force"))
 	r.Force = force
 
 	// Base currency check
-	baseCurrency := c.Query("base")
+	baseCurrency := param("base")
 	if len(baseCurrency) != 3 {
 		return errors.New("unsupported base currency. Received: " + baseCurrency)
 	}
 	r.BaseCurrency = baseCurrency
 
 	// Provider code check
-	providerCode := c.Param("provider")
+	providerCode := param("provider")
 	r.ProviderCode = providerCode
 
 	// Provider location name
 	r.ProviderLocationName = config.Providers[providerCode].Location
 
+	// Rate type check
+	rateType := param("rate_type")
+	if rateType == "" {
+		rateType = util.RateTypeMid
+	}
+	if rateType != util.RateTypeMid && rateType != util.RateTypeBid && rateType != util.RateTypeAsk {
+		return errors.New("unsupported rate type. Allows only(mid, bid, ask). Received: " + rateType)
+	}
+	r.RateType = rateType
+
 	// Symbols check
-	symbolsStr := c.Query("symbols")
+	symbolsStr := param("symbols")
 	symbols := strings.Split(symbolsStr, ",")
 	symbols = util.UniqueStringSlice(symbols)
 	r.Symbols = symbols
 	return nil
 }
 
+// GetRequestedDate returns date passed by client
+func (r *RatesRequest) GetRequestedDate() time.Time {
+	if r.RequestedDate.IsZero() {
+		return r.Date
+	}
+	return r.RequestedDate
+}
+
+// GetRateType returns requested rate type, mid by default
+func (r *RatesRequest) GetRateType() string {
+	if r.RateType == "" {
+		return util.RateTypeMid
+	}
+	return r.RateType
+}
+
 // IsEqualCurrencyRequest detects such type of request
 func (r *RatesRequest) IsEqualCurrencyRequest() bool {
 	return len(r.Symbols) == 1 && r.BaseCurrency == r.Symbols[0]
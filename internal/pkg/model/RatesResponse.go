@@ -1,14 +1,29 @@
 package model
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"github.com/shopspring/decimal"
+)
 
 // RatesResponse represents result for RatesRequest
 type RatesResponse struct {
 	// Rates exchange rate data for the currencies you have requested.
-	Rates map[string]float64 `json:"rates"`
+	Rates map[string]decimal.Decimal `json:"rates"`
 
 	// Timestamp when provider generate rates in Rates if it single-pair, or first pair if multiple symbols in Rates
 	Timestamp int64 `json:"timestamp"`
+
+	// Provider code of provider which actually served rates (if it differs from requested one)
+	Provider string `json:"provider,omitempty"`
+
+	// Sources codes of providers contributed to every rate (for consensus provider only)
+	Sources map[string][]string `json:"sources,omitempty"`
+
+	// Stale is true if rates are last-known-good ones, served instead of actual
+	Stale bool `json:"-"`
+
+	// Age of stale rates in seconds
+	Age int64 `json:"-"`
 }
 
 // String returns string representation of JSON of this key structure
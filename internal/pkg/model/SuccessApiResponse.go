@@ -1,7 +1,9 @@
 package model
 
 import (
+	"encoding/json"
 	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
 	"time"
 )
 
@@ -16,36 +18,83 @@ type SuccessApiResponse struct {
 	// Date date for which historical rates were requested.
 	Date string `json:"date"`
 
+	// EffectiveDate date of provider's publication the rates actually belong to.
+	EffectiveDate string `json:"effective_date"`
+
 	// Timestamp the exact date and time (UNIX time stamp) the given rates were collected.
 	Timestamp int64 `json:"timestamp"`
 
 	// Base the three-letter currency code of the base currency used for this request.
 	Base string `json:"base"`
 
+	// RateType the side of rates: mid, bid or ask.
+	RateType string `json:"rate_type"`
+
 	// Rates exchange rate data for the currencies you have requested.
-	Rates map[string]float64 `json:"rates"`
+	Rates map[string]decimal.Decimal `json:"rates" swaggertype:"object,number"`
+
+	// Provider the code of underlying provider served the rates (for aggregating providers only).
+	Provider string `json:"provider,omitempty"`
+
+	// Sources the codes of providers contributed to consensus rate of every currency.
+	Sources map[string][]string `json:"sources,omitempty"`
+
+	// Stale true if the rates are last-known-good ones, served because actual rates are not available yet.
+	Stale bool `json:"stale,omitempty"`
+
+	// Age the age of stale rates in seconds.
+	Age int64 `json:"age,omitempty"`
+
+	// format of rates: number or string
+	format string
 }
 
 // NewSuccessApiResponse constructor
 func NewSuccessApiResponse(serviceRequest RatesRequest, serviceResponse RatesResponse) *SuccessApiResponse {
 	return &SuccessApiResponse{
-		Success:    true,
-		Historical: serviceRequest.Endpoint == util.EndpointHistorical,
-		Date:       serviceRequest.Date.Format(util.DateFormatEu),
-		Timestamp:  serviceResponse.Timestamp,
-		Base:       serviceRequest.BaseCurrency,
-		Rates:      serviceResponse.Rates,
+		Success:       true,
+		Historical:    serviceRequest.Endpoint == util.EndpointHistorical,
+		Date:          serviceRequest.GetRequestedDate().Format(util.DateFormatEu),
+		EffectiveDate: serviceRequest.Date.Format(util.DateFormatEu),
+		Timestamp:     serviceResponse.Timestamp,
+		Base:          serviceRequest.BaseCurrency,
+		RateType:      serviceRequest.GetRateType(),
+		Rates:         serviceResponse.Rates,
+		Provider:      serviceResponse.Provider,
+		Sources:       serviceResponse.Sources,
+		Stale:         serviceResponse.Stale,
+		Age:           serviceResponse.Age,
+		format:        serviceRequest.Format,
 	}
 }
 
 // NewSuccessApiResponseCurrencyEquals returns response if base currency = quoted currency
 func NewSuccessApiResponseCurrencyEquals(serviceRequest RatesRequest) *SuccessApiResponse {
 	return &SuccessApiResponse{
-		Success:    true,
-		Historical: serviceRequest.Endpoint == util.EndpointHistorical,
-		Date:       serviceRequest.Date.Format(util.DateFormatEu),
-		Timestamp:  time.Now().Unix(),
-		Base:       serviceRequest.BaseCurrency,
-		Rates:      map[string]float64{serviceRequest.BaseCurrency: 1},
+		Success:       true,
+		Historical:    serviceRequest.Endpoint == util.EndpointHistorical,
+		Date:          serviceRequest.GetRequestedDate().Format(util.DateFormatEu),
+		EffectiveDate: serviceRequest.Date.Format(util.DateFormatEu),
+		Timestamp:     time.Now().Unix(),
+		Base:          serviceRequest.BaseCurrency,
+		RateType:      serviceRequest.GetRateType(),
+		Rates:         map[string]decimal.Decimal{serviceRequest.BaseCurrency: decimal.NewFromInt(1)},
+		format:        serviceRequest.Format,
+	}
+}
+
+// MarshalJSON encodes rates as JSON strings if string format requested, as JSON numbers otherwise
+func (a SuccessApiResponse) MarshalJSON() ([]byte, error) {
+	type response SuccessApiResponse
+	if a.format != util.FormatString {
+		return json.Marshal(response(a))
+	}
+	rates := make(map[string]string)
+	for currency, rate := range a.Rates {
+		rates[currency] = rate.String()
 	}
+	return json.Marshal(struct {
+		response
+		Rates map[string]string `json:"rates"`
+	}{response(a), rates})
 }
@@ -0,0 +1,22 @@
+package model
+
+// KeyUsage is number of requests of API access key owner
+type KeyUsage struct {
+	// Name the name of key owner.
+	Name string `json:"name"`
+
+	// Requests the number of requests in month.
+	Requests int `json:"requests"`
+}
+
+// UsageApiResponse represents response of API access keys usage admin API
+type UsageApiResponse struct {
+	// Success true or false depending on whether or not your API request has succeeded.
+	Success bool `json:"success"`
+
+	// Month the month of usage (format YYYY-MM).
+	Month string `json:"month"`
+
+	// Usage the usage counters of key owners.
+	Usage []KeyUsage `json:"usage"`
+}
@@ -0,0 +1,416 @@
+// Package pipeline serves rates requests through multi-level cache and providers, shared by REST and gRPC APIs
+package pipeline
+
+import (
+	"github.com/eko/gocache/cache"
+	"github.com/eko/gocache/store"
+	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/cache/snapshot"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"golang.org/x/sync/singleflight"
+	"net/http"
+	"time"
+)
+
+// ImmutableMaxAge is cache lifetime of immutable rates: snapshots of served rates and rates as known at past instant
+const ImmutableMaxAge = 365 * 24 * time.Hour
+
+// HistoricalMaxAge is cache lifetime of historical rates for past dates. They can be corrected via Admin API,
+// so they are not immutable
+const HistoricalMaxAge = 24 * time.Hour
+
+// Pipeline resolves rates request date, loads rates from cache or requests provider and saves them to cache
+type Pipeline struct {
+	config       *model.ApplicationConfig
+	cache        *cache.ChainCache
+	registry     *provider.Registry
+	availability *provider.Availability
+	snapshots    *snapshot.LatestSnapshots
+	snapshotRepo *repository.SnapshotRepository
+	rateRepo     *repository.RateRepository
+	clock        util.Clock
+
+	// In-flight provider requests, keyed by cache key
+	inFlight singleflight.Group
+}
+
+// BuildPipeline /* *Pipeline
+func BuildPipeline(
+	config *model.ApplicationConfig,
+	cache *cache.ChainCache,
+	registry *provider.Registry,
+	availability *provider.Availability,
+	snapshots *snapshot.LatestSnapshots,
+	snapshotRepo *repository.SnapshotRepository,
+	rateRepo *repository.RateRepository,
+	clock util.Clock) (*Pipeline, error) {
+	return NewPipeline(config, cache, registry, availability, snapshots, snapshotRepo, rateRepo, clock), nil
+}
+
+// NewPipeline is the constructor
+func NewPipeline(
+	config *model.ApplicationConfig,
+	cache *cache.ChainCache,
+	registry *provider.Registry,
+	availability *provider.Availability,
+	snapshots *snapshot.LatestSnapshots,
+	snapshotRepo *repository.SnapshotRepository,
+	rateRepo *repository.RateRepository,
+	clock util.Clock) *Pipeline {
+	return &Pipeline{
+		config:       config,
+		cache:        cache,
+		registry:     registry,
+		availability: availability,
+		snapshots:    snapshots,
+		snapshotRepo: snapshotRepo,
+		rateRepo:     rateRepo,
+		clock:        clock,
+	}
+}
+
+// isDebug returns bool value is debug mode on?
+func (p *Pipeline) isDebug() bool {
+	return p.config.Engine.Mode == gin.DebugMode
+}
+
+// GetHistorical returns historical rates of requested date or instant and their cache lifetime.
+// Request date is replaced with the date rates actually belong to
+func (p *Pipeline) GetHistorical(serviceRequest *model.RatesRequest) (model.RatesResponse, time.Duration, error) {
+	var (
+		err             error
+		prov            provider.RatesProvider
+		serviceResponse = model.RatesResponse{
+			Timestamp: 0,
+			Rates:     make(map[string]decimal.Decimal),
+		}
+	)
+
+	// Init provider
+	if prov, err = p.registry.GetProvider(serviceRequest.ProviderCode); err != nil {
+		return serviceResponse, 0, err
+	}
+
+	if !serviceRequest.At.IsZero() {
+		// Point-in-time request: rates served at the instant, if they were saved as snapshots
+		if serviceRequest.At.After(p.clock.Now()) {
+			return serviceResponse, 0, customerror.NewBadRequestError("instant (at) can not be in the future")
+		}
+		at := serviceRequest.At.In(prov.GetLocation())
+		serviceRequest.RequestedDate = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		if snapshotResponse, ok, err := p.findServedSnapshot(prov, serviceRequest); err != nil {
+			return serviceResponse, 0, err
+		} else if ok {
+			return snapshotResponse, ImmutableMaxAge, nil
+		}
+
+		// Otherwise rates of the date, which was the latest published at the instant
+		serviceRequest.Date = p.availability.GetDateAt(prov, serviceRequest.At)
+	} else {
+		// Result for request today's historical rates
+		today := p.availability.GetToday(prov)
+		if util.IsDateEquals(serviceRequest.Date, today) {
+			serviceRequest.Date = today.AddDate(0, 0, -1)
+		}
+
+		// Resolve non-publication date (weekend, bank holiday) to the previous publication day
+		if serviceRequest.Resolve == util.ResolvePrevious {
+			serviceRequest.Date = prov.GetCapabilities().Calendar.GetPreviousPublicationDay(serviceRequest.Date)
+		}
+	}
+
+	// Rates as known at instant are served from L2 cache with revisions only
+	if !serviceRequest.KnownAt.IsZero() {
+		if serviceResponse, err = p.getKnownAt(*serviceRequest); err != nil {
+			return serviceResponse, 0, err
+		}
+		return serviceResponse, ImmutableMaxAge, nil
+	}
+
+	// Cache get
+	cacheKey, _ := serviceRequest.String()
+	cacheValue, err := p.cache.Get(cacheKey)
+
+	if err != nil && !customerror.IsNotFound(err) {
+		return serviceResponse, 0, err
+	}
+	if cacheValue != nil && !serviceRequest.Force {
+		if p.isDebug() {
+			logger.LogSuccess("Found", "CACHE")
+		}
+		// Unmarshall
+		if err := serviceResponse.FromString(cacheValue.(string)); err != nil {
+			return serviceResponse, 0, err
+		}
+	} else {
+		if p.isDebug() {
+			logger.LogWarning("Not found", "CACHE")
+		}
+		// Get rates
+		if p.isDebug() {
+			logger.LogWarning("Request provider \""+serviceRequest.ProviderCode+"\" API", "API")
+		}
+		fetchRequest := *serviceRequest
+		serviceResponse, err = p.fetchRates(fetchRequest, cacheKey, func() (model.RatesResponse, error) {
+			return prov.GetHistoricalRates(fetchRequest)
+		})
+		if err != nil {
+			return serviceResponse, 0, err
+		}
+	}
+
+	// Historical rates for past dates are rarely changed, today's ones and ones re-fetched to detect corrections
+	// can be changed by provider
+	maxAge := p.GetLatestMaxAge()
+	refetchDays := prov.GetConfig().RefetchDays
+	if serviceRequest.Date.Before(p.availability.GetToday(prov).AddDate(0, 0, -refetchDays)) {
+		maxAge = HistoricalMaxAge
+	}
+	return serviceResponse, maxAge, nil
+}
+
+// GetLatest returns latest rates and their cache lifetime. Request date is replaced with the date of latest rates,
+// endpoint - with historical one, if end-of-day provider has not published today's rates yet
+func (p *Pipeline) GetLatest(serviceRequest *model.RatesRequest) (model.RatesResponse, time.Duration, error) {
+	var (
+		err             error
+		prov            provider.RatesProvider
+		serviceResponse = model.RatesResponse{
+			Timestamp: 0,
+			Rates:     make(map[string]decimal.Decimal),
+		}
+		date time.Time
+	)
+	if !serviceRequest.At.IsZero() {
+		return serviceResponse, 0, customerror.NewBadRequestError("instant (at) is supported by historical and snapshot endpoints only")
+	}
+
+	// Init provider
+	if prov, err = p.registry.GetProvider(serviceRequest.ProviderCode); err != nil {
+		return serviceResponse, 0, err
+	}
+
+	// Correct service request (Define correct date)
+	date = p.availability.GetToday(prov)
+	if prov.GetCapabilities().IsEndOfDay() {
+		date = p.availability.GetLatestDate(prov)
+		if !util.IsDateEquals(date, p.availability.GetToday(prov)) {
+			serviceRequest.Endpoint = util.EndpointHistorical
+		}
+	}
+	serviceRequest.Date = date
+
+	// BaseCurrency = QuotedCurrency ?
+	if serviceRequest.IsEqualCurrencyRequest() {
+		serviceResponse.Rates[serviceRequest.BaseCurrency] = decimal.NewFromInt(1)
+		serviceResponse.Timestamp = p.clock.Now().Unix()
+		return serviceResponse, 0, nil
+	}
+
+	// Cache get
+	cacheKey, _ := serviceRequest.String()
+	cacheValue, err := p.cache.Get(cacheKey)
+
+	if err != nil && !customerror.IsNotFound(err) {
+		return serviceResponse, 0, err
+	}
+
+	if cacheValue != nil && !serviceRequest.Force {
+		if p.isDebug() {
+			logger.LogSuccess("Found", "CACHE")
+		}
+		// Unmarshall
+		if err := serviceResponse.FromString(cacheValue.(string)); err != nil {
+			return serviceResponse, 0, err
+		}
+	} else {
+		if p.isDebug() {
+			logger.LogWarning("Not found", "CACHE")
+		}
+		// Get rates and remember them as last-known-good ones
+		fetchRequest := *serviceRequest
+		fetch := func() (model.RatesResponse, error) {
+			fetchResponse, err := prov.GetLatestRates(fetchRequest)
+			if err == nil {
+				p.snapshots.Save(fetchRequest, fetchResponse)
+				p.recordSnapshot(fetchRequest, fetchResponse)
+			}
+			return fetchResponse, err
+		}
+
+		// Stale-while-revalidate: serve last-known-good rates and refresh them in background
+		snapshotMaxAge := p.GetLatestMaxAge() + time.Duration(p.config.Latest.StaleWhileRevalidate)*time.Second
+		if snapshotResponse, ok := p.getSnapshot(serviceRequest, snapshotMaxAge); ok {
+			serviceResponse = snapshotResponse
+			if serviceResponse.Stale {
+				go p.revalidate(fetchRequest, cacheKey, fetch)
+			}
+		} else if serviceResponse, err = p.fetchRates(fetchRequest, cacheKey, fetch); err != nil {
+			// Stale-if-error: serve last-known-good rates if provider is not available
+			staleIfError := time.Duration(p.config.Latest.StaleIfError) * time.Second
+			if !customerror.IsRetryable(err) {
+				return serviceResponse, 0, err
+			}
+			if serviceResponse, ok = p.getSnapshot(serviceRequest, staleIfError); !ok {
+				return serviceResponse, 0, err
+			}
+			logger.LogWarning("Provider \""+serviceRequest.ProviderCode+"\" failed, stale rates served. "+err.Error(), "API")
+		}
+	}
+
+	// Stale rates should not be cached
+	if serviceResponse.Stale {
+		return serviceResponse, 0, nil
+	}
+	return serviceResponse, p.GetLatestMaxAge(), nil
+}
+
+// GetLatestMaxAge returns cache lifetime of latest rates, the same as L1 cache expiration
+func (p *Pipeline) GetLatestMaxAge() time.Duration {
+	return time.Duration(p.config.L1Cache.DefaultExpiration) * time.Second
+}
+
+// fetchRates requests rates from provider and saves them to cache. Concurrent requests with the same cache key
+// are coalesced: only one of them calls provider, the others wait for it and share its result
+func (p *Pipeline) fetchRates(
+	serviceRequest model.RatesRequest,
+	cacheKey string,
+	fetch func() (model.RatesResponse, error)) (model.RatesResponse, error) {
+	result, err, shared := p.inFlight.Do(cacheKey, func() (interface{}, error) {
+		serviceResponse, err := fetch()
+		if err != nil {
+			return serviceResponse, err
+		}
+
+		// Cache set
+		if !serviceRequest.Force {
+			expiration := time.Duration(p.config.L1Cache.DefaultExpiration) * time.Second
+
+			// Marshall
+			cacheValueStr, err := serviceResponse.String()
+			if err != nil {
+				return serviceResponse, err
+			}
+			if err = p.setCache(cacheKey, cacheValueStr, expiration); err != nil {
+				return model.RatesResponse{}, err
+			}
+			if p.isDebug() {
+				logger.LogSuccess("Set cache value with key "+cacheKey, "CACHE")
+			}
+		}
+		return serviceResponse, nil
+	})
+	if shared && p.isDebug() {
+		logger.LogSuccess("Shared in-flight provider response with key "+cacheKey, "API")
+	}
+	return result.(model.RatesResponse), err
+}
+
+// setCache saves rates to all cache levels, starting from the persistent one. Rates rejected by L2 cache
+// (quarantined as suspicious) are neither saved to L1 cache nor served
+func (p *Pipeline) setCache(cacheKey string, cacheValueStr string, expiration time.Duration) error {
+	caches := p.cache.GetCaches()
+	for i := len(caches) - 1; i >= 0; i-- {
+		err := caches[i].Set(cacheKey, cacheValueStr, &store.Options{Expiration: expiration})
+		if customerror.GetStatus(err) == http.StatusUnprocessableEntity {
+			return err
+		}
+	}
+	return nil
+}
+
+// findServedSnapshot returns latest rates served at requested instant, if they were saved as snapshots.
+// Snapshots are used for real-time providers only, as end-of-day provider's latest rates are historical ones.
+// Request date is replaced with the date snapshot belongs to
+func (p *Pipeline) findServedSnapshot(prov provider.RatesProvider, serviceRequest *model.RatesRequest) (model.RatesResponse, bool, error) {
+	if !p.config.Latest.Snapshots || prov.GetCapabilities().IsEndOfDay() {
+		return model.RatesResponse{}, false, nil
+	}
+	serviceResponse, date, err := p.snapshotRepo.FindAt(*serviceRequest, serviceRequest.At)
+	if customerror.IsNotFound(err) {
+		return serviceResponse, false, nil
+	}
+	if err != nil {
+		return serviceResponse, false, err
+	}
+	serviceRequest.Date = date
+	return serviceResponse, true, nil
+}
+
+// recordSnapshot persists latest rates fetched from provider, if snapshots are enabled
+func (p *Pipeline) recordSnapshot(serviceRequest model.RatesRequest, serviceResponse model.RatesResponse) {
+	if !p.config.Latest.Snapshots {
+		return
+	}
+	if err := p.snapshotRepo.Record(serviceRequest, serviceResponse, p.clock.Now()); err != nil {
+		logger.LogError("Latest rates snapshot is not saved. "+err.Error(), "DB")
+	}
+}
+
+// getSnapshot returns last-known-good latest rates not older than maxAge. Request date is replaced with the date
+// snapshot belongs to. Rates older than L1 cache expiration are marked as stale
+func (p *Pipeline) getSnapshot(serviceRequest *model.RatesRequest, maxAge time.Duration) (model.RatesResponse, bool) {
+	if serviceRequest.Force || maxAge <= 0 {
+		return model.RatesResponse{}, false
+	}
+	serviceResponse, date, age, ok := p.snapshots.Get(*serviceRequest, maxAge)
+	if !ok {
+		return serviceResponse, false
+	}
+	if age > p.GetLatestMaxAge() {
+		serviceResponse.Stale = true
+		serviceResponse.Age = int64(age.Seconds())
+	}
+	serviceRequest.Date = date
+	if p.isDebug() {
+		logger.LogSuccess("Found last-known-good rates, age "+age.String(), "SNAPSHOT")
+	}
+	return serviceResponse, true
+}
+
+// revalidate refreshes rates in background after stale ones were served
+func (p *Pipeline) revalidate(
+	serviceRequest model.RatesRequest,
+	cacheKey string,
+	fetch func() (model.RatesResponse, error)) {
+	if _, err := p.fetchRates(serviceRequest, cacheKey, fetch); err != nil {
+		logger.LogWarning("Background refresh of provider \""+serviceRequest.ProviderCode+"\" rates failed. "+err.Error(), "API")
+	}
+}
+
+// getKnownAt returns stored historical rates as they were known at requested instant
+func (p *Pipeline) getKnownAt(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
+	var symbols []string
+	if serviceRequest.KnownAt.After(p.clock.Now()) {
+		return model.RatesResponse{}, customerror.NewBadRequestError("instant (known_at) can not be in the future")
+	}
+	for _, symbol := range serviceRequest.Symbols {
+		if symbol != serviceRequest.BaseCurrency {
+			symbols = append(symbols, symbol)
+		}
+	}
+	rates, providerGeneratedTime, err := p.rateRepo.FindKnownAt(
+		serviceRequest.ProviderCode,
+		serviceRequest.BaseCurrency,
+		symbols,
+		serviceRequest.GetRateType(),
+		serviceRequest.Date,
+		serviceRequest.KnownAt)
+	if err != nil {
+		return model.RatesResponse{}, err
+	}
+	if len(rates) < len(symbols) {
+		return model.RatesResponse{}, customerror.NewNotFoundError("rates of " + serviceRequest.Date.Format(util.DateFormatEu) +
+			" were not known at " + serviceRequest.KnownAt.Format(time.RFC3339))
+	}
+	if len(symbols) != len(serviceRequest.Symbols) {
+		rates[serviceRequest.BaseCurrency] = decimal.NewFromInt(1)
+	}
+	return model.RatesResponse{Rates: rates, Timestamp: providerGeneratedTime.Unix()}, nil
+}
@@ -0,0 +1,110 @@
+// Package preload implements historical rates preload jobs and gap detection
+package preload
+
+import (
+	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"time"
+)
+
+// Gap is publication day with missing or partially stored historical rates
+type Gap struct {
+	// Publication day
+	Date time.Time
+
+	// Number of stored pairs
+	Stored int
+
+	// Number of expected pairs, 0 if unknown
+	Expected int
+}
+
+// IsMissing returns true if there are no stored rates for the day
+func (g Gap) IsMissing() bool {
+	return g.Stored == 0
+}
+
+// GapScanner finds gaps in stored historical rates of provider
+type GapScanner struct {
+	rates        *repository.RateRepository
+	quarantine   *repository.QuarantineRepository
+	availability *provider.Availability
+}
+
+// BuildGapScanner /* *GapScanner
+func BuildGapScanner(
+	rates *repository.RateRepository,
+	quarantine *repository.QuarantineRepository,
+	availability *provider.Availability) (*GapScanner, error) {
+	return NewGapScanner(rates, quarantine, availability), nil
+}
+
+// NewGapScanner constructor
+func NewGapScanner(
+	rates *repository.RateRepository,
+	quarantine *repository.QuarantineRepository,
+	availability *provider.Availability) *GapScanner {
+	return &GapScanner{
+		rates:        rates,
+		quarantine:   quarantine,
+		availability: availability,
+	}
+}
+
+// GetDefaultRange returns date range to scan: from provider's historical_start_date to the latest published date
+func (s *GapScanner) GetDefaultRange(p provider.RatesProvider) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(util.DateFormatEu, p.GetConfig().HistoricalStartDate, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid historical_start_date of provider " + p.GetCode() + ". " + err.Error())
+	}
+	return start, s.availability.GetLatestDate(p), nil
+}
+
+// Scan returns publication days in date range, which have no stored rates or fewer stored pairs than supported.
+// Pairs are counted for pivot currency only, without pivot currency only missing days are detected.
+// Quarantined days are not gaps: they are fetched, but wait for review
+func (s *GapScanner) Scan(p provider.RatesProvider, start time.Time, end time.Time) ([]Gap, error) {
+	var (
+		gaps     []Gap
+		expected int
+	)
+	capabilities := p.GetCapabilities()
+	pivot := capabilities.PivotCurrency
+	if pivot != "" {
+		for _, currency := range p.GetSupportedCurrencies() {
+			if currency != pivot {
+				expected++
+			}
+		}
+	}
+	counts, err := s.rates.CountByDate(p.GetCode(), pivot, start, end)
+	if err != nil {
+		return gaps, err
+	}
+	quarantined, err := s.quarantine.FindDates(p.GetCode(), start, end)
+	if err != nil {
+		return gaps, err
+	}
+	maxMissing := p.GetConfig().Anomaly.MaxMissingCurrencies
+	for _, date := range capabilities.Calendar.FilterPublicationDays(util.GetDateRangeArr(start, end)) {
+		stored := counts[date.Format(util.DateFormatEu)]
+		if quarantined[date.Format(util.DateFormatEu)] {
+			continue
+		}
+		if stored == 0 || (expected > 0 && expected-stored > maxMissing) {
+			gaps = append(gaps, Gap{Date: date, Stored: stored, Expected: expected})
+		}
+	}
+	return gaps, nil
+}
+
+// GetDates returns dates of gaps
+func (s *GapScanner) GetDates(gaps []Gap) []time.Time {
+	var dates []time.Time
+	for _, gap := range gaps {
+		dates = append(dates, gap.Date)
+	}
+	return dates
+}
@@ -0,0 +1,313 @@
+package preload
+
+import (
+	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/leader"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JobManager runs preload jobs: fetches provider's historical rates for every date of job, tracks state of dates
+// in database and resumes unfinished jobs after restart. Jobs are run by preload leader only
+type JobManager struct {
+	config   *model.ApplicationConfig
+	registry *provider.Registry
+	jobs     *repository.JobRepository
+	elector  *leader.Elector
+	clock    util.Clock
+
+	// Ids of jobs running by this instance
+	mu      sync.Mutex
+	running map[uint]bool
+}
+
+// BuildJobManager /* *JobManager
+func BuildJobManager(
+	config *model.ApplicationConfig,
+	registry *provider.Registry,
+	jobs *repository.JobRepository,
+	elector *leader.Elector,
+	clock util.Clock) (*JobManager, error) {
+	return NewJobManager(config, registry, jobs, elector, clock), nil
+}
+
+// NewJobManager constructor
+func NewJobManager(
+	config *model.ApplicationConfig,
+	registry *provider.Registry,
+	jobs *repository.JobRepository,
+	elector *leader.Elector,
+	clock util.Clock) *JobManager {
+	return &JobManager{
+		config:   config,
+		registry: registry,
+		jobs:     jobs,
+		elector:  elector,
+		clock:    clock,
+		running:  make(map[uint]bool),
+	}
+}
+
+// StartRange creates and runs in background job, which preloads provider's publication days in date range
+func (m *JobManager) StartRange(p provider.RatesProvider, start time.Time, end time.Time) (*entity.PreloadJob, error) {
+	if end.Before(start) {
+		return nil, errors.New("end date should not be before start date")
+	}
+	dates := p.GetCapabilities().Calendar.FilterPublicationDays(util.GetDateRangeArr(start, end))
+	return m.Start(p, dates)
+}
+
+// Start creates and runs in background job, which preloads passed dates.
+// If instance is not preload leader, job stays pending until leader resumes it
+func (m *JobManager) Start(p provider.RatesProvider, dates []time.Time) (*entity.PreloadJob, error) {
+	if len(dates) == 0 {
+		return nil, errors.New("there are no publication days to preload")
+	}
+	now := m.clock.Now().UTC()
+	job := &entity.PreloadJob{
+		Provider:    p.GetCode(),
+		StartDate:   dates[0].Format(util.DateFormatEu),
+		EndDate:     dates[len(dates)-1].Format(util.DateFormatEu),
+		Status:      entity.JobStatusPending,
+		CreatedTime: now,
+	}
+	for _, date := range dates {
+		job.Dates = append(job.Dates, entity.PreloadJobDate{
+			RateDate:    date.Format(util.DateFormatEu),
+			Status:      entity.JobDateStatusPending,
+			UpdatedTime: now,
+		})
+	}
+	if err := m.jobs.Create(job); err != nil {
+		return nil, err
+	}
+	if !m.elector.IsLeader() {
+		logger.LogWarning("Preload job "+strconv.Itoa(int(job.ID))+" will be run by preload leader", "JOB")
+		return job, nil
+	}
+	go m.run(*job)
+	return job, nil
+}
+
+// Retry runs again in background failed dates of finished job.
+// If instance is not preload leader, job stays pending until leader resumes it
+func (m *JobManager) Retry(jobID uint) (*entity.PreloadJob, error) {
+	job, err := m.jobs.Find(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == entity.JobStatusPending || job.Status == entity.JobStatusRunning {
+		return nil, customerror.NewUnprocessableError("job " + strconv.Itoa(int(jobID)) + " is not finished")
+	}
+	now := m.clock.Now().UTC()
+	reset, err := m.jobs.ResetFailedDates(jobID, now)
+	if err != nil {
+		return nil, err
+	}
+	if reset == 0 {
+		return nil, customerror.NewUnprocessableError("job " + strconv.Itoa(int(jobID)) + " has no failed dates")
+	}
+	job.Status = entity.JobStatusPending
+	job.FinishedTime = nil
+	if err = m.jobs.SaveJob(job); err != nil {
+		return nil, err
+	}
+	if job, err = m.jobs.Find(jobID); err != nil {
+		return nil, err
+	}
+	logger.LogWarning("Retry "+strconv.FormatInt(reset, 10)+" failed dates of preload job "+strconv.Itoa(int(jobID)), "JOB")
+	if !m.elector.IsLeader() {
+		logger.LogWarning("Preload job "+strconv.Itoa(int(job.ID))+" will be run by preload leader", "JOB")
+		return job, nil
+	}
+	go m.run(*job)
+	return job, nil
+}
+
+// Resume runs in background unfinished jobs, which were interrupted by restart, created by other instance
+// or left by previous leader
+func (m *JobManager) Resume() error {
+	if !m.elector.IsLeader() {
+		return nil
+	}
+	jobs, err := m.jobs.FindUnfinished()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if m.isRunning(job.ID) {
+			continue
+		}
+		logger.LogWarning("Resume preload job "+strconv.Itoa(int(job.ID))+" of provider "+job.Provider, "JOB")
+		go m.run(job)
+	}
+	return nil
+}
+
+// HasUnfinished returns true if provider has pending or running job
+func (m *JobManager) HasUnfinished(providerCode string) (bool, error) {
+	jobs, err := m.jobs.FindUnfinished()
+	if err != nil {
+		return false, err
+	}
+	for _, job := range jobs {
+		if job.Provider == providerCode {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// run preloads pending dates of job by parallel workers
+func (m *JobManager) run(job entity.PreloadJob) {
+	if !m.lock(job.ID) {
+		return
+	}
+	defer m.unlock(job.ID)
+	jobID := strconv.Itoa(int(job.ID))
+
+	p, err := m.registry.GetProvider(job.Provider)
+	if err != nil {
+		m.finish(&job, entity.JobStatusFailed)
+		logger.LogError("Preload job "+jobID+" failed. "+err.Error(), "JOB")
+		return
+	}
+
+	// Start
+	now := m.clock.Now().UTC()
+	job.Status = entity.JobStatusRunning
+	if job.StartedTime == nil {
+		job.StartedTime = &now
+	}
+	if err = m.jobs.SaveJob(&job); err != nil {
+		logger.LogError("Preload job "+jobID+" state is not saved. "+err.Error(), "JOB")
+	}
+	logger.LogSuccess("Preload job "+jobID+" of provider "+job.Provider+" started: "+job.StartDate+" - "+job.EndDate, "JOB")
+
+	// Workers
+	parallelism := m.config.Collector.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	dates := make(chan *entity.PreloadJobDate)
+	wg := sync.WaitGroup{}
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for date := range dates {
+				m.preloadDate(p, date)
+			}
+		}()
+	}
+	for i := range job.Dates {
+		// Leader is changed, new leader will resume job
+		if !m.elector.IsLeader() {
+			break
+		}
+		if job.Dates[i].Status == entity.JobDateStatusPending {
+			dates <- &job.Dates[i]
+		}
+	}
+	close(dates)
+	wg.Wait()
+	if !m.elector.IsLeader() {
+		logger.LogWarning("Preload job "+jobID+" is interrupted, preload leadership is lost", "JOB")
+		return
+	}
+
+	// Finish
+	counts, err := m.jobs.CountDates(job.ID)
+	if err != nil {
+		logger.LogError("Preload job "+jobID+" state is not loaded. "+err.Error(), "JOB")
+	}
+	if counts[entity.JobDateStatusFailed] > 0 {
+		m.finish(&job, entity.JobStatusFailed)
+		logger.LogError("Preload job "+jobID+" finished, "+strconv.Itoa(counts[entity.JobDateStatusFailed])+" dates failed", "JOB")
+		return
+	}
+	m.finish(&job, entity.JobStatusCompleted)
+	logger.LogSuccess("Preload job "+jobID+" completed", "JOB")
+}
+
+// preloadDate fetches and saves rates of one date, retries failed fetch up to max attempts
+func (m *JobManager) preloadDate(p provider.RatesProvider, date *entity.PreloadJobDate) {
+	maxAttempts := m.config.Collector.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	rateDate, err := time.ParseInLocation(util.DateFormatEu, date.RateDate, time.UTC)
+	if err == nil {
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			m.sleep()
+			date.Attempts++
+			if _, _, _, err = p.PreloadRates(rateDate, true); err == nil {
+				break
+			}
+		}
+	}
+	date.Status = entity.JobDateStatusDone
+	date.Error = ""
+	if err != nil {
+		date.Status = entity.JobDateStatusFailed
+		date.Error = err.Error()
+		logger.LogError("Rates of provider "+p.GetCode()+" for "+date.RateDate+" are not preloaded. "+err.Error(), "JOB")
+	} else {
+		logger.LogSuccess("Fetched for date "+date.RateDate, "JOB")
+	}
+	date.UpdatedTime = m.clock.Now().UTC()
+	if err = m.jobs.SaveDate(date); err != nil {
+		logger.LogError("Preload job date state is not saved. "+err.Error(), "JOB")
+	}
+}
+
+// sleep waits random delay between requests of provider API
+func (m *JobManager) sleep() {
+	if delay := m.config.Collector.RandomDelay; delay > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(delay) * int64(time.Second))))
+	}
+}
+
+// finish saves final job status
+func (m *JobManager) finish(job *entity.PreloadJob, status string) {
+	now := m.clock.Now().UTC()
+	job.Status = status
+	job.FinishedTime = &now
+	if err := m.jobs.SaveJob(job); err != nil {
+		logger.LogError("Preload job "+strconv.Itoa(int(job.ID))+" state is not saved. "+err.Error(), "JOB")
+	}
+}
+
+// lock marks job as running by this instance, returns false if it is already running
+func (m *JobManager) lock(jobID uint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running[jobID] {
+		return false
+	}
+	m.running[jobID] = true
+	return true
+}
+
+// isRunning returns true if job is running by this instance
+func (m *JobManager) isRunning(jobID uint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running[jobID]
+}
+
+// unlock marks job as not running by this instance
+func (m *JobManager) unlock(jobID uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.running, jobID)
+}
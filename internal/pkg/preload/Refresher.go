@@ -0,0 +1,138 @@
+package preload
+
+import (
+	"github.com/fatih/color"
+	"github.com/netandreus/go-forex-rates/internal/pkg/leader"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"log"
+	"time"
+)
+
+// Refresher starts preload of provider's historical rates from the last stored date to the latest published one.
+// Target date is calculated at run time by clock of Availability, in provider's location and by its publication time,
+// so scheduled refresh can be driven by any clock
+type Refresher struct {
+	rates        *repository.RateRepository
+	availability *provider.Availability
+	jobManager   *JobManager
+	watcher      *Watcher
+	elector      *leader.Elector
+}
+
+// BuildRefresher /* *Refresher
+func BuildRefresher(
+	rates *repository.RateRepository,
+	availability *provider.Availability,
+	jobManager *JobManager,
+	watcher *Watcher,
+	elector *leader.Elector) (*Refresher, error) {
+	return NewRefresher(rates, availability, jobManager, watcher, elector), nil
+}
+
+// NewRefresher constructor
+func NewRefresher(
+	rates *repository.RateRepository,
+	availability *provider.Availability,
+	jobManager *JobManager,
+	watcher *Watcher,
+	elector *leader.Elector) *Refresher {
+	return &Refresher{
+		rates:        rates,
+		availability: availability,
+		jobManager:   jobManager,
+		watcher:      watcher,
+		elector:      elector,
+	}
+}
+
+// RunScheduled is scheduled refresh: preloads rates up to the latest published date and polls provider
+// until rates of its current date are published. Runs on preload leader only
+func (r *Refresher) RunScheduled(p provider.RatesProvider) {
+	if !r.elector.IsLeader() {
+		return
+	}
+	log.Print("Cron event triggered")
+	r.Refresh(p)
+
+	// Poll provider until rates of its current date are published
+	if date, ok := r.getWatchDate(p); ok {
+		r.watcher.Watch(p, date)
+	}
+}
+
+// getWatchDate returns provider's current date in its location, if it is publication day
+func (r *Refresher) getWatchDate(p provider.RatesProvider) (time.Time, bool) {
+	today := r.availability.GetToday(p)
+	return today, p.GetCapabilities().Calendar.IsPublicationDay(today)
+}
+
+// getDateRange returns the latest published date of provider and publication days from start date up to it
+func (r *Refresher) getDateRange(p provider.RatesProvider, startDate time.Time) (time.Time, []time.Time) {
+	endDate := r.availability.GetLatestDate(p)
+	return endDate, p.GetCapabilities().Calendar.FilterPublicationDays(util.GetDateRangeArr(startDate, endDate))
+}
+
+// Refresh starts job, which preloads rates from the day after the last stored date
+// to the latest published date of provider, and re-fetches refetch_days the latest stored days
+func (r *Refresher) Refresh(p provider.RatesProvider) {
+	// Unfinished job of provider will continue preload
+	if unfinished, err := r.jobManager.HasUnfinished(p.GetCode()); err != nil || unfinished {
+		return
+	}
+
+	// Form array of dates
+	startDate, err := r.GetStartDate(p)
+	if err != nil {
+		logger.LogError(err.Error(), "JOB")
+		return
+	}
+	endDate, dateRange := r.getDateRange(p, startDate)
+	refetchRange := r.getRefetchRange(p, startDate)
+	if len(dateRange) == 0 && len(refetchRange) == 0 {
+		log.Print("Currency rates database is filled")
+		return
+	}
+
+	// Add job
+	if len(dateRange) > 0 {
+		message := "Currency rates database needs filling"
+		message += " from " + startDate.Format(util.DateFormatEu)
+		message += " to " + endDate.Format(util.DateFormatEu)
+		log.Print(color.YellowString(message))
+	}
+	if _, err = r.jobManager.Start(p, append(refetchRange, dateRange...)); err != nil {
+		logger.LogError(err.Error(), "JOB")
+	}
+}
+
+// getRefetchRange returns refetch_days publication days before start date, they are re-fetched
+// to detect rates corrected by provider
+func (r *Refresher) getRefetchRange(p provider.RatesProvider, startDate time.Time) []time.Time {
+	days := p.GetConfig().RefetchDays
+	if days <= 0 || p.GetConfig().HistoricalStartDate == startDate.Format(util.DateFormatEu) {
+		return nil
+	}
+	dateRange := util.GetDateRangeArr(startDate.AddDate(0, 0, -days), startDate.AddDate(0, 0, -1))
+	return p.GetCapabilities().Calendar.FilterPublicationDays(dateRange)
+}
+
+// GetStartDate returns the day after the last stored date of provider's historical rates,
+// or historical_start_date if there are no stored rates
+func (r *Refresher) GetStartDate(p provider.RatesProvider) (time.Time, error) {
+	latest, err := r.rates.FindLatestDate(p.GetCode())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if latest == "" {
+		// initial start date
+		return time.ParseInLocation(util.DateFormatEu, p.GetConfig().HistoricalStartDate, time.UTC)
+	}
+	date, err := time.ParseInLocation(util.DateFormatEu, latest, time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,199 @@
+package preload
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is Clock stopped at passed instant, its tickers tick on demand
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) util.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ticker := &fakeTicker{c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance moves clock forward and ticks all its tickers
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, ticker := range c.tickers {
+		select {
+		case ticker.c <- c.now:
+		default:
+		}
+	}
+}
+
+type fakeTicker struct {
+	c chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+}
+
+// fakeProvider is provider publishing rates at 18:00 Dubai time, weekend is changed since 2022-01-01
+type fakeProvider struct {
+	code   string
+	config model.ProviderConfig
+}
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{
+		code: "fake",
+		config: model.ProviderConfig{
+			Location:            "Asia/Dubai",
+			RatesGeneratedTime:  "18:00",
+			HistoricalStartDate: "2021-01-01",
+			Weekend:             []string{"Friday", "Saturday"},
+			WeekendRules: []model.WeekendRuleConfig{
+				{Since: "2022-01-01", Weekend: []string{"Saturday", "Sunday"}},
+			},
+			Retry: model.RetryConfig{Interval: 10, Deadline: 90},
+		},
+	}
+}
+
+func (p *fakeProvider) GetCode() string {
+	return p.code
+}
+
+func (p *fakeProvider) GetConfig() model.ProviderConfig {
+	return p.config
+}
+
+func (p *fakeProvider) GetHistoricalRates(ratesRequest model.RatesRequest) (model.RatesResponse, error) {
+	return model.RatesResponse{}, nil
+}
+
+func (p *fakeProvider) GetLatestRates(ratesRequest model.RatesRequest) (model.RatesResponse, error) {
+	return model.RatesResponse{}, nil
+}
+
+func (p *fakeProvider) PreloadRates(date time.Time, save bool) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
+	return nil, nil, time.Time{}, nil
+}
+
+func (p *fakeProvider) GetRateGenerationTime() time.Time {
+	generationTime, _ := time.Parse(util.TimeFormat, p.config.RatesGeneratedTime)
+	return generationTime
+}
+
+func (p *fakeProvider) GetSupportedCurrencies() []string {
+	return nil
+}
+
+func (p *fakeProvider) IsRequestValid(ratesRequest model.RatesRequest) (bool, error) {
+	return true, nil
+}
+
+func (p *fakeProvider) GetLocation() *time.Location {
+	location, _ := time.LoadLocation(p.config.Location)
+	return location
+}
+
+func (p *fakeProvider) GetCapabilities() provider.Capabilities {
+	return provider.Capabilities{
+		Calendar: provider.NewCalendar(p.config.Weekend, p.config.WeekendRules, p.config.Holidays),
+	}
+}
+
+func (p *fakeProvider) GetPrecision() int32 {
+	return util.DefaultPrecision
+}
+
+func (p *fakeProvider) BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate decimal.Decimal, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate {
+	return nil
+}
+
+func date(value string) time.Time {
+	parsed, _ := time.Parse(util.DateFormatEu, value)
+	return parsed
+}
+
+func TestRefresherWatchDate(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		date time.Time
+		ok   bool
+	}{
+		{"date in provider's location", time.Date(2022, 1, 4, 21, 30, 0, 0, time.UTC), date("2022-01-05"), true},
+		{"date in UTC before midnight in provider's location", time.Date(2022, 1, 4, 19, 30, 0, 0, time.UTC), date("2022-01-04"), true},
+		{"weekend before weekend rule", time.Date(2021, 12, 30, 21, 0, 0, 0, time.UTC), date("2021-12-31"), false},
+		{"weekday since weekend rule", time.Date(2022, 1, 6, 21, 0, 0, 0, time.UTC), date("2022-01-07"), true},
+		{"weekend since weekend rule", time.Date(2022, 1, 7, 21, 0, 0, 0, time.UTC), date("2022-01-08"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			refresher := NewRefresher(nil, provider.NewAvailability(&fakeClock{now: test.now}), nil, nil, nil)
+			watchDate, ok := refresher.getWatchDate(newFakeProvider())
+			if !watchDate.Equal(test.date) || ok != test.ok {
+				t.Errorf("getWatchDate() = %s, %t, want %s, %t",
+					watchDate.Format(util.DateFormatEu), ok, test.date.Format(util.DateFormatEu), test.ok)
+			}
+		})
+	}
+}
+
+func TestRefresherDateRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		now     time.Time
+		start   time.Time
+		endDate time.Time
+		dates   []string
+	}{
+		{"before publication time", time.Date(2022, 1, 10, 13, 59, 0, 0, time.UTC),
+			date("2022-01-06"), date("2022-01-07"), []string{"2022-01-06", "2022-01-07"}},
+		{"at publication time", time.Date(2022, 1, 10, 14, 0, 0, 0, time.UTC),
+			date("2022-01-06"), date("2022-01-10"), []string{"2022-01-06", "2022-01-07", "2022-01-10"}},
+		{"across weekend rule", time.Date(2022, 1, 3, 15, 0, 0, 0, time.UTC),
+			date("2021-12-29"), date("2022-01-03"), []string{"2021-12-29", "2021-12-30", "2022-01-03"}},
+		{"the latest date is stored", time.Date(2022, 1, 9, 15, 0, 0, 0, time.UTC),
+			date("2022-01-08"), date("2022-01-07"), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			refresher := NewRefresher(nil, provider.NewAvailability(&fakeClock{now: test.now}), nil, nil, nil)
+			endDate, dateRange := refresher.getDateRange(newFakeProvider(), test.start)
+			var dates []string
+			for _, d := range dateRange {
+				dates = append(dates, d.Format(util.DateFormatEu))
+			}
+			if !endDate.Equal(test.endDate) || len(dates) != len(test.dates) {
+				t.Fatalf("getDateRange() = %s, %v, want %s, %v",
+					endDate.Format(util.DateFormatEu), dates, test.endDate.Format(util.DateFormatEu), test.dates)
+			}
+			for i := range dates {
+				if dates[i] != test.dates[i] {
+					t.Errorf("getDateRange() dates = %v, want %v", dates, test.dates)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package preload
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/leader"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Watcher polls provider until rates of the date are stored, re-fetching them every retry interval
+// up to retry deadline. Polling does not create preload jobs. Alert is logged if rates are not published till deadline.
+// Retry interval and deadline are measured by clock
+type Watcher struct {
+	gapScanner *GapScanner
+	jobManager *JobManager
+	elector    *leader.Elector
+	clock      util.Clock
+
+	// Watched provider dates
+	mu       sync.Mutex
+	watching map[string]bool
+}
+
+// BuildWatcher /* *Watcher
+func BuildWatcher(
+	gapScanner *GapScanner,
+	jobManager *JobManager,
+	elector *leader.Elector,
+	clock util.Clock) (*Watcher, error) {
+	return NewWatcher(gapScanner, jobManager, elector, clock), nil
+}
+
+// NewWatcher constructor
+func NewWatcher(
+	gapScanner *GapScanner,
+	jobManager *JobManager,
+	elector *leader.Elector,
+	clock util.Clock) *Watcher {
+	return &Watcher{
+		gapScanner: gapScanner,
+		jobManager: jobManager,
+		elector:    elector,
+		clock:      clock,
+		watching:   make(map[string]bool),
+	}
+}
+
+// Watch starts polling of provider's rates for the date in background, if provider's retry is configured
+// and the date is not watched yet
+func (w *Watcher) Watch(p provider.RatesProvider, date time.Time) {
+	retry := p.GetConfig().Retry
+	if retry.Interval <= 0 || retry.Deadline <= 0 {
+		return
+	}
+	key := p.GetCode() + "|" + date.Format(util.DateFormatEu)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watching[key] {
+		return
+	}
+	w.watching[key] = true
+	go func() {
+		defer w.unwatch(key)
+		w.watch(p, date, time.Duration(retry.Interval)*time.Minute, w.GetDeadline(p))
+	}()
+}
+
+// GetDeadline returns instant, when polling of provider's rates started now stops and alert is logged
+func (w *Watcher) GetDeadline(p provider.RatesProvider) time.Time {
+	return w.clock.Now().Add(time.Duration(p.GetConfig().Retry.Deadline) * time.Minute)
+}
+
+// watch polls provider's rates for the date every interval till they are stored or deadline is reached
+func (w *Watcher) watch(p provider.RatesProvider, date time.Time, interval time.Duration, deadline time.Time) {
+	dateStr := date.Format(util.DateFormatEu)
+	ticker := w.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C() {
+		if !w.elector.IsLeader() {
+			return
+		}
+		if w.isStored(p, date) {
+			logger.LogSuccess("Rates of provider "+p.GetCode()+" for "+dateStr+" are published", "RETRY")
+			return
+		}
+		if !w.clock.Now().Before(deadline) {
+			logger.LogError("Rates of provider "+p.GetCode()+" for "+dateStr+" are not published in "+
+				strconv.Itoa(p.GetConfig().Retry.Deadline)+" minutes after schedule", "ALERT")
+			return
+		}
+		// Unfinished job of provider is fetching rates already
+		if unfinished, err := w.jobManager.HasUnfinished(p.GetCode()); err != nil || unfinished {
+			continue
+		}
+		logger.LogWarning("Rates of provider "+p.GetCode()+" for "+dateStr+" are not published yet, retrying", "RETRY")
+		if _, _, _, err := p.PreloadRates(date, true); err != nil {
+			if !customerror.IsRetryable(err) {
+				logger.LogError("Rates of provider "+p.GetCode()+" for "+dateStr+" are not preloaded. "+err.Error(), "RETRY")
+				return
+			}
+			logger.LogWarning(err.Error(), "RETRY")
+			continue
+		}
+		if w.isStored(p, date) {
+			logger.LogSuccess("Rates of provider "+p.GetCode()+" for "+dateStr+" are published", "RETRY")
+			return
+		}
+	}
+}
+
+// isStored returns true if rates of the date are stored without gaps
+func (w *Watcher) isStored(p provider.RatesProvider, date time.Time) bool {
+	gaps, err := w.gapScanner.Scan(p, date, date)
+	if err != nil {
+		logger.LogError(err.Error(), "RETRY")
+		return false
+	}
+	return len(gaps) == 0
+}
+
+// unwatch marks provider date as not watched
+func (w *Watcher) unwatch(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.watching, key)
+}
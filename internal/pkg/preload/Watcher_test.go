@@ -0,0 +1,55 @@
+package preload
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/leader"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"testing"
+	"time"
+)
+
+func TestWatcherDeadline(t *testing.T) {
+	now := time.Date(2022, 1, 10, 14, 0, 0, 0, time.UTC)
+	watcher := NewWatcher(nil, nil, nil, &fakeClock{now: now})
+	if deadline := watcher.GetDeadline(newFakeProvider()); !deadline.Equal(now.Add(90 * time.Minute)) {
+		t.Errorf("GetDeadline() = %s, want %s", deadline, now.Add(90*time.Minute))
+	}
+}
+
+func TestWatcherNotConfigured(t *testing.T) {
+	p := newFakeProvider()
+	p.config.Retry = model.RetryConfig{}
+	watcher := NewWatcher(nil, nil, nil, &fakeClock{})
+	watcher.Watch(p, date("2022-01-10"))
+	if len(watcher.watching) != 0 {
+		t.Errorf("Watch() started polling without retry configured")
+	}
+}
+
+func TestWatcherStopsWithoutLeadership(t *testing.T) {
+	config := &model.ApplicationConfig{}
+	config.Leader.Enabled = true
+	clock := &fakeClock{now: time.Date(2022, 1, 10, 14, 0, 0, 0, time.UTC)}
+	watcher := NewWatcher(nil, nil, leader.NewElector(config, nil), clock)
+	p := newFakeProvider()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.watch(p, date("2022-01-10"), 10*time.Minute, watcher.GetDeadline(p))
+	}()
+	for {
+		clock.mu.Lock()
+		started := len(clock.tickers) > 0
+		clock.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(10 * time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watch() did not stop after leadership was lost")
+	}
+}
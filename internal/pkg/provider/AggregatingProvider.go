@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"time"
+)
+
+// AggregatingProvider is base of providers, which serve rates of underlying providers (composite, consensus)
+type AggregatingProvider struct {
+	BaseProvider
+	registry *Registry
+	codes    []string
+}
+
+// NewAggregatingProvider constructor. Codes are underlying providers in configured order
+func NewAggregatingProvider(availability *Availability, registry *Registry, codes []string) AggregatingProvider {
+	return AggregatingProvider{
+		BaseProvider: NewBaseProvider(availability),
+		registry:     registry,
+		codes:        codes,
+	}
+}
+
+// GetProviders returns registered underlying providers in configured order
+func (a *AggregatingProvider) GetProviders() []RatesProvider {
+	var providers []RatesProvider
+	for _, code := range a.codes {
+		if prov, err := a.registry.GetProvider(code); err == nil {
+			providers = append(providers, prov)
+		}
+	}
+	return providers
+}
+
+// GetSupportedCurrencies returns configured currencies or union of underlying providers currencies
+func (a *AggregatingProvider) GetSupportedCurrencies(p RatesProvider) []string {
+	var currencies []string
+	if len(p.GetConfig().SupportedCurrencies) > 0 {
+		return p.GetConfig().SupportedCurrencies
+	}
+	for _, prov := range a.GetProviders() {
+		currencies = append(currencies, prov.GetSupportedCurrencies()...)
+	}
+	return util.UniqueStringSlice(currencies)
+}
+
+// GetCapabilities returns default capabilities, rate types are published by any of underlying providers
+func (a *AggregatingProvider) GetCapabilities(p RatesProvider) Capabilities {
+	var rateTypes []string
+	capabilities := a.BaseProvider.GetCapabilities(p)
+	for _, prov := range a.GetProviders() {
+		rateTypes = append(rateTypes, prov.GetCapabilities().RateTypes...)
+	}
+	capabilities.RateTypes = util.UniqueStringSlice(rateTypes)
+	return capabilities
+}
+
+// GetRequest returns request of underlying provider, the same as direct request of its rates of resolved date
+func (a *AggregatingProvider) GetRequest(serviceRequest model.RatesRequest, prov RatesProvider) model.RatesRequest {
+	request := serviceRequest
+	request.ProviderCode = prov.GetCode()
+	request.ProviderLocationName = prov.GetConfig().Location
+	request.At = time.Time{}
+	request.KnownAt = time.Time{}
+	request.Resolve = ""
+	return request
+}
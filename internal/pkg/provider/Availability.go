@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"time"
+)
+
+// Availability answers is provider's rates for the date already published
+type Availability struct {
+	clock util.Clock
+}
+
+// BuildAvailability constructor
+func BuildAvailability(clock util.Clock) (*Availability, error) {
+	return NewAvailability(clock), nil
+}
+
+// NewAvailability constructor
+func NewAvailability(clock util.Clock) *Availability {
+	return &Availability{
+		clock: clock,
+	}
+}
+
+// GetToday returns today's date in provider's location (at 00:00 UTC, as all requested dates)
+func (a *Availability) GetToday(p RatesProvider) time.Time {
+	now := a.clock.Now().In(p.GetLocation())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// IsTodayPublished returns true if provider's rates_generated_time for today already passed in provider's location
+func (a *Availability) IsTodayPublished(p RatesProvider) bool {
+	return a.isPublishedAt(p, a.clock.Now())
+}
+
+// GetPublicationTime returns today's instant of provider's rates_generated_time in provider's location
+func (a *Availability) GetPublicationTime(p RatesProvider) time.Time {
+	location := p.GetLocation()
+	now := a.clock.Now().In(location)
+	generationTime := p.GetRateGenerationTime()
+	return time.Date(now.Year(), now.Month(), now.Day(),
+		generationTime.Hour(), generationTime.Minute(), generationTime.Second(), 0, location)
+}
+
+// GetLatestDate returns date of the latest published provider's rates
+func (a *Availability) GetLatestDate(p RatesProvider) time.Time {
+	return a.GetDateAt(p, a.clock.Now())
+}
+
+// GetDateAt returns date of provider's rates, which were the latest published ones at passed instant:
+// the instant's date in provider's location if rates were already published at that time, previous day otherwise.
+// Non-publication days are resolved to the previous publication day
+func (a *Availability) GetDateAt(p RatesProvider, at time.Time) time.Time {
+	local := at.In(p.GetLocation())
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	if !a.isPublishedAt(p, at) {
+		date = date.AddDate(0, 0, -1)
+	}
+	return p.GetCapabilities().Calendar.GetPreviousPublicationDay(date)
+}
+
+// isPublishedAt returns true if provider's rates_generated_time of the instant's date already passed at that instant
+func (a *Availability) isPublishedAt(p RatesProvider, at time.Time) bool {
+	location := p.GetLocation()
+	local := at.In(location)
+	generationTime := p.GetRateGenerationTime()
+	publicationTime := time.Date(local.Year(), local.Month(), local.Day(),
+		generationTime.Hour(), generationTime.Minute(), generationTime.Second(), 0, location)
+	return !local.Before(publicationTime)
+}
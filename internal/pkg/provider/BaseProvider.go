@@ -2,14 +2,27 @@ package provider
 
 import (
 	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
 	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
 	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"net/http"
+	"strconv"
 	"time"
 )
 
 // BaseProvider implements base provider functionality
 type BaseProvider struct {
+	availability *Availability
+}
+
+// NewBaseProvider constructor
+func NewBaseProvider(availability *Availability) BaseProvider {
+	return BaseProvider{
+		availability: availability,
+	}
 }
 
 // IsRequestValid validates API call to provider.
@@ -17,12 +30,15 @@ func (b *BaseProvider) IsRequestValid(p RatesProvider, ratesRequest model.RatesR
 	baseCurrency := ratesRequest.BaseCurrency
 	date := ratesRequest.Date.Format(util.DateFormatEu)
 	symbols := ratesRequest.Symbols
-	if date != "" {
-		dateObject, _ := time.ParseInLocation(util.DateFormatEu, date, p.GetLocation())
-		// Check date is not in future
-		if dateObject.After(util.GetToday(p.GetLocation())) {
-			return false, errors.New("date should not be in future")
-		}
+	capabilities := p.GetCapabilities()
+
+	// Check endpoint is supported
+	if ratesRequest.Endpoint != "" && !capabilities.SupportsEndpoint(ratesRequest.Endpoint) {
+		return false, errors.New("endpoint " + ratesRequest.Endpoint + " does not supported by provider " + p.GetCode())
+	}
+	// Check date is not in future
+	if date != "" && ratesRequest.Date.After(b.availability.GetToday(p)) {
+		return false, errors.New("date should not be in future")
 	}
 	supportedCurrencies := p.GetSupportedCurrencies()
 
@@ -38,8 +54,19 @@ func (b *BaseProvider) IsRequestValid(p RatesProvider, ratesRequest model.RatesR
 		}
 	}
 
+	// Check rate type is supported
+	if !capabilities.SupportsRateType(ratesRequest.GetRateType()) {
+		return false, errors.New("rate type " + ratesRequest.GetRateType() + " does not supported by provider " + p.GetCode())
+	}
+
+	// Check pivot currency is in baseCurrency OR ONLY pivot currency in symbols
+	pivot := capabilities.PivotCurrency
+	if pivot != "" && !(baseCurrency == pivot || (len(symbols) == 1 && symbols[0] == pivot)) {
+		return false, errors.New("provider needs " + pivot + " is in baseCurrency OR ONLY " + pivot + " in symbols")
+	}
+
 	// Check date not before provider start date
-	providerStartDateStr := p.GetConfig().HistoricalStartDate
+	providerStartDateStr := capabilities.HistoryStartDate
 	if providerStartDateStr != "" {
 		providerStartDate, _ := time.Parse(util.DateFormatEu, providerStartDateStr)
 		if ratesRequest.Date.Before(providerStartDate) {
@@ -51,7 +78,7 @@ func (b *BaseProvider) IsRequestValid(p RatesProvider, ratesRequest model.RatesR
 }
 
 // BuildEntity builds entity with given rates
-func (b *BaseProvider) BuildEntity(endpoint string, providerCode string, baseCurrency string, quotedCurrency string, rate float64, rateTime time.Time, providerTime time.Time) *entity.CurrencyRate {
+func (b *BaseProvider) BuildEntity(endpoint string, providerCode string, baseCurrency string, quotedCurrency string, rate decimal.Decimal, rateTime time.Time, providerTime time.Time) *entity.CurrencyRate {
 	return &entity.CurrencyRate{
 		Provider:              providerCode,
 		Endpoint:              endpoint,
@@ -60,8 +87,55 @@ func (b *BaseProvider) BuildEntity(endpoint string, providerCode string, baseCur
 		RateDate:              rateTime.Format(util.DateFormatEu),
 		ProviderGeneratedTime: providerTime.UTC(),
 		RequestTime:           time.Now().UTC(),
-		Value:                 util.ToFixed(rate, 6),
+		Value:                 rate,
+		RateType:              util.RateTypeMid,
+	}
+}
+
+// GetCapabilities returns default capabilities: all endpoints, real-time latest rates, any base currency
+func (b *BaseProvider) GetCapabilities(p RatesProvider) Capabilities {
+	return Capabilities{
+		Endpoints:           []string{util.EndpointHistorical, util.EndpointLatest},
+		LatestMode:          LatestModeRealTime,
+		RateTypes:           []string{util.RateTypeMid},
+		HistoryStartDate:    p.GetConfig().HistoricalStartDate,
+		PublicationLocation: p.GetLocation(),
+		PublicationTime:     p.GetRateGenerationTime(),
+		Calendar:            NewCalendar(p.GetConfig().Weekend, p.GetConfig().WeekendRules, p.GetConfig().Holidays),
+	}
+}
+
+// Fetch makes provider API GET request and returns response body. Errors are typed (timeout, outage, bad response)
+func (b *BaseProvider) Fetch(p RatesProvider, url string) ([]byte, error) {
+	timeout := p.GetConfig().Timeout
+	if timeout <= 0 {
+		timeout = util.DefaultProviderTimeout
+	}
+	client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, customerror.NewProviderRequestError(p.GetCode(), err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, customerror.NewProviderRequestError(p.GetCode(), err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, customerror.NewUnavailableError("provider " + p.GetCode() + " is unavailable. HTTP status " + strconv.Itoa(resp.StatusCode))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, customerror.NewProviderError("provider " + p.GetCode() + " request failed. HTTP status " + strconv.Itoa(resp.StatusCode))
+	}
+	return body, nil
+}
+
+// GetPrecision returns number of decimal places of provider's rates
+func (b *BaseProvider) GetPrecision(p RatesProvider) int32 {
+	if precision := p.GetConfig().Precision; precision > 0 {
+		return precision
 	}
+	return util.DefaultPrecision
 }
 
 // GetRateGenerationTime returns time, when provider generates history rates for today and we can fetch it.
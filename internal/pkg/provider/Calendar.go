@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxNonPublicationDays limits search of previous publication day
+const maxNonPublicationDays = 366
+
+// Calendar is provider's publication calendar: business days without weekends and bank holidays
+type Calendar struct {
+	weekend  map[time.Weekday]bool
+	rules    []weekendRule
+	holidays map[string]bool
+}
+
+// weekendRule is weekend effective since date
+type weekendRule struct {
+	since   time.Time
+	weekend map[time.Weekday]bool
+}
+
+// NewCalendar constructor. Weekend is list of weekday names (Saturday, Sunday) used before the first of weekend rules,
+// rules - weekend changes with effective dates, holidays - list of dates (YYYY-MM-DD)
+func NewCalendar(weekend []string, rules []model.WeekendRuleConfig, holidays []string) *Calendar {
+	calendar := &Calendar{
+		weekend:  parseWeekend(weekend),
+		holidays: make(map[string]bool),
+	}
+	for _, rule := range rules {
+		since, err := time.Parse(util.DateFormatEu, rule.Since)
+		if err != nil {
+			logger.LogError("Weekend rule is skipped, wrong date \""+rule.Since+"\"", "CONFIG")
+			continue
+		}
+		calendar.rules = append(calendar.rules, weekendRule{since: since, weekend: parseWeekend(rule.Weekend)})
+	}
+	sort.Slice(calendar.rules, func(i, j int) bool {
+		return calendar.rules[i].since.Before(calendar.rules[j].since)
+	})
+	for _, holiday := range holidays {
+		calendar.holidays[holiday] = true
+	}
+	return calendar
+}
+
+// parseWeekend returns set of weekdays by their names
+func parseWeekend(names []string) map[time.Weekday]bool {
+	weekend := make(map[time.Weekday]bool)
+	for _, name := range names {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(day.String(), name) {
+				weekend[day] = true
+			}
+		}
+	}
+	return weekend
+}
+
+// IsPublicationDay returns true if provider publishes rates for passed date
+func (c *Calendar) IsPublicationDay(date time.Time) bool {
+	if c.getWeekend(date)[date.Weekday()] {
+		return false
+	}
+	return !c.holidays[date.Format(util.DateFormatEu)]
+}
+
+// getWeekend returns weekend effective at passed date
+func (c *Calendar) getWeekend(date time.Time) map[time.Weekday]bool {
+	weekend := c.weekend
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	for _, rule := range c.rules {
+		if day.Before(rule.since) {
+			break
+		}
+		weekend = rule.weekend
+	}
+	return weekend
+}
+
+// GetPreviousPublicationDay returns passed date if it is publication day, or the closest publication day before it
+func (c *Calendar) GetPreviousPublicationDay(date time.Time) time.Time {
+	for i := 0; i < maxNonPublicationDays; i++ {
+		if c.IsPublicationDay(date) {
+			return date
+		}
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// FilterPublicationDays returns only publication days from passed dates
+func (c *Calendar) FilterPublicationDays(dates []time.Time) []time.Time {
+	var filtered []time.Time
+	for _, date := range dates {
+		if c.IsPublicationDay(date) {
+			filtered = append(filtered, date)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"time"
+)
+
+// Latest rates modes
+const (
+	// LatestModeRealTime provider returns real-time rates on latest endpoint
+	LatestModeRealTime = "real_time"
+
+	// LatestModeEndOfDay provider publishes rates once a day, latest rates are the last published historical ones
+	LatestModeEndOfDay = "end_of_day"
+)
+
+// Capabilities describes what provider is able to serve
+type Capabilities struct {
+	// Supported endpoints (historical, latest)
+	Endpoints []string
+
+	// If not empty, base currency should be pivot one, or symbols should contain only pivot currency
+	PivotCurrency string
+
+	// Is latest rate real-time or end-of-day one
+	LatestMode string
+
+	// Published rate types (mid, bid, ask)
+	RateTypes []string
+
+	// The earliest date of available historical rates (format YYYY-MM-DD), empty if unknown
+	HistoryStartDate string
+
+	// Time location of provider's publication schedule
+	PublicationLocation *time.Location
+
+	// Time, when provider publishes historical rates for today
+	PublicationTime time.Time
+
+	// Days when provider publishes rates
+	Calendar *Calendar
+
+	// Provider fetches all rates of the day at once and saves them to L2 cache (PreloadRates is implemented)
+	Preload bool
+}
+
+// SupportsEndpoint returns true if provider can serve passed endpoint
+func (c Capabilities) SupportsEndpoint(endpoint string) bool {
+	return util.Contains(c.Endpoints, endpoint)
+}
+
+// SupportsRateType returns true if provider publishes passed rate type
+func (c Capabilities) SupportsRateType(rateType string) bool {
+	return util.Contains(c.RateTypes, rateType)
+}
+
+// IsEndOfDay returns true if latest rates are end-of-day ones
+func (c Capabilities) IsEndOfDay() bool {
+	return c.LatestMode == LatestModeEndOfDay
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/shopspring/decimal"
 	"time"
 )
 
@@ -12,10 +13,12 @@ type RatesProvider interface {
 	GetConfig() model.ProviderConfig
 	GetHistoricalRates(ratesRequest model.RatesRequest) (model.RatesResponse, error)
 	GetLatestRates(ratesRequest model.RatesRequest) (model.RatesResponse, error)
-	PreloadRates(date time.Time, save bool) (map[string]float64, map[string]float64, time.Time, error)
+	PreloadRates(date time.Time, save bool) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error)
 	GetRateGenerationTime() time.Time
 	GetSupportedCurrencies() []string
 	IsRequestValid(ratesRequest model.RatesRequest) (bool, error)
 	GetLocation() *time.Location
-	BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate float64, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate
+	GetCapabilities() Capabilities
+	GetPrecision() int32
+	BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate decimal.Decimal, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate
 }
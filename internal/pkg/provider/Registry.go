@@ -1,7 +1,7 @@
 package provider
 
 import (
-	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
 )
 
 // Registry is providers container
@@ -30,5 +30,5 @@ func (r *Registry) GetProvider(code string) (RatesProvider, error) {
 			return provider, nil
 		}
 	}
-	return nil, errors.New("provider with code " + code + "does not registered")
+	return nil, customerror.NewUnknownProviderError("provider with code " + code + " does not registered")
 }
@@ -0,0 +1,178 @@
+// Package composite implements fallback provider, which asks underlying providers in turn
+package composite
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/pipeline"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+// Code composite provider code
+const Code = "composite"
+
+// Provider implements composite provider structure
+type Provider struct {
+	provider.AggregatingProvider
+	code     string
+	config   model.ProviderConfig
+	registry *provider.Registry
+	pipeline *pipeline.Pipeline
+}
+
+// New constructor
+func New(
+	registry *provider.Registry,
+	pipeline *pipeline.Pipeline,
+	config *model.ApplicationConfig,
+	availability *provider.Availability) *Provider {
+	// Build provider
+	provider := &Provider{
+		AggregatingProvider: provider.NewAggregatingProvider(availability, registry, config.Providers[Code].Providers),
+		code:                Code,
+		config:              config.Providers[Code],
+		registry:            registry,
+		pipeline:            pipeline,
+	}
+	return provider
+}
+
+// GetCode returns provider code
+func (p Provider) GetCode() string {
+	return p.code
+}
+
+// GetConfig returns provider config
+func (p Provider) GetConfig() model.ProviderConfig {
+	return p.config
+}
+
+// GetHistoricalRates returns historical rates from the first underlying provider able to serve them
+func (p Provider) GetHistoricalRates(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
+	// Validate request
+	if _, err := p.IsRequestValid(serviceRequest); err != nil {
+		return model.RatesResponse{}, customerror.NewUnprocessableError("request is invalid. " + err.Error())
+	}
+	return p.fallback(serviceRequest, func(request model.RatesRequest) (model.RatesResponse, error) {
+		serviceResponse, _, err := p.pipeline.GetHistorical(&request)
+		return serviceResponse, err
+	})
+}
+
+// GetLatestRates returns latest rates from the first underlying provider able to serve them
+func (p Provider) GetLatestRates(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
+	// Validate request
+	if _, err := p.IsRequestValid(serviceRequest); err != nil {
+		return model.RatesResponse{}, customerror.NewUnprocessableError("request is invalid. " + err.Error())
+	}
+	return p.fallback(serviceRequest, func(request model.RatesRequest) (model.RatesResponse, error) {
+		serviceResponse, _, err := p.pipeline.GetLatest(&request)
+		return serviceResponse, err
+	})
+}
+
+// PreloadRates does nothing, underlying providers preload their own rates
+func (p Provider) PreloadRates(date time.Time, save bool) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
+	return make(map[string]decimal.Decimal), make(map[string]decimal.Decimal), time.Time{}, nil
+}
+
+// GetRateGenerationTime returns historical rates generated time on provider side
+func (p Provider) GetRateGenerationTime() time.Time {
+	return p.BaseProvider.GetRateGenerationTime(p.config.RatesGeneratedTime)
+}
+
+// GetSupportedCurrencies returns configured currencies or union of underlying providers currencies
+func (p Provider) GetSupportedCurrencies() []string {
+	return p.AggregatingProvider.GetSupportedCurrencies(p)
+}
+
+// IsRequestValid validates API call to provider.
+func (p Provider) IsRequestValid(ratesRequest model.RatesRequest) (bool, error) {
+	return p.BaseProvider.IsRequestValid(p, ratesRequest)
+}
+
+// GetCapabilities returns provider capabilities, rate types are published by any of underlying providers
+func (p Provider) GetCapabilities() provider.Capabilities {
+	return p.AggregatingProvider.GetCapabilities(p)
+}
+
+// GetPrecision returns number of decimal places of provider's rates
+func (p Provider) GetPrecision() int32 {
+	return p.BaseProvider.GetPrecision(p)
+}
+
+// GetLocation returns location for current provider
+func (p Provider) GetLocation() *time.Location {
+	location, err := time.LoadLocation(p.config.Location)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// BuildEntity builds entity with given rates
+func (p Provider) BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate decimal.Decimal, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate {
+	e := p.BaseProvider.BuildEntity(endpoint, p.GetCode(), baseCurrency, quotedCurrency, rate, rateDate, providerDate)
+	return e
+}
+
+// fallback calls underlying providers in configured order until one of them returns all requested rates.
+// Every underlying provider is requested through pipeline, so its rates are cached under its own key
+// and shared with direct requests of the provider
+func (p Provider) fallback(
+	serviceRequest model.RatesRequest,
+	fetch func(request model.RatesRequest) (model.RatesResponse, error)) (model.RatesResponse, error) {
+	var (
+		failures      []string
+		isUnavailable bool // at least one provider failed because of outage, not because of request
+	)
+
+	for _, code := range p.config.Providers {
+		prov, err := p.registry.GetProvider(code)
+		if err != nil {
+			failures = append(failures, code+": "+err.Error())
+			continue
+		}
+
+		// Underlying provider does not support such request (currency, date etc.)
+		if _, err = prov.IsRequestValid(serviceRequest); err != nil {
+			failures = append(failures, code+": "+err.Error())
+			continue
+		}
+
+		serviceResponse, err := fetch(p.GetRequest(serviceRequest, prov))
+		if err != nil {
+			failures = append(failures, code+": "+err.Error())
+			isUnavailable = isUnavailable || customerror.IsRetryable(err)
+			continue
+		}
+
+		// Provider has no data for some of symbols at this date
+		if missing := p.getMissingSymbols(serviceResponse, serviceRequest.Symbols); len(missing) > 0 {
+			failures = append(failures, code+": no rates for "+strings.Join(missing, ","))
+			continue
+		}
+		serviceResponse.Provider = code
+		return serviceResponse, nil
+	}
+	message := "no one provider can serve request. " + strings.Join(failures, "; ")
+	if isUnavailable {
+		return model.RatesResponse{}, customerror.NewUnavailableError(message)
+	}
+	return model.RatesResponse{}, customerror.NewUnprocessableError(message)
+}
+
+// getMissingSymbols returns symbols with absent or zero rates in response
+func (p Provider) getMissingSymbols(serviceResponse model.RatesResponse, symbols []string) []string {
+	var missing []string
+	for _, symbol := range symbols {
+		if rate, ok := serviceResponse.Rates[symbol]; !ok || rate.IsZero() {
+			missing = append(missing, symbol)
+		}
+	}
+	return missing
+}
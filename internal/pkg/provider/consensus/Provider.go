@@ -0,0 +1,269 @@
+// Package consensus implements aggregating provider, which returns median rate of several providers
+package consensus
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/pipeline"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Code consensus provider code
+const Code = "consensus"
+
+// DefaultTolerance is used if tolerance is not configured
+const DefaultTolerance = 0.001
+
+// MinOutlierSources is minimal number of sources to detect outlier. Median of two rates is their average,
+// so both of them deviate from it equally and none can be rejected
+const MinOutlierSources = 3
+
+// sourceResponse is rates response of one underlying provider
+type sourceResponse struct {
+	code     string
+	response model.RatesResponse
+	err      error
+}
+
+// Provider implements consensus provider structure
+type Provider struct {
+	provider.AggregatingProvider
+	code     string
+	config   model.ProviderConfig
+	registry *provider.Registry
+	pipeline *pipeline.Pipeline
+}
+
+// New constructor
+func New(
+	registry *provider.Registry,
+	pipeline *pipeline.Pipeline,
+	config *model.ApplicationConfig,
+	availability *provider.Availability) *Provider {
+	// Build provider
+	provider := &Provider{
+		AggregatingProvider: provider.NewAggregatingProvider(availability, registry, config.Providers[Code].Providers),
+		code:                Code,
+		config:              config.Providers[Code],
+		registry:            registry,
+		pipeline:            pipeline,
+	}
+	return provider
+}
+
+// GetCode returns provider code
+func (p Provider) GetCode() string {
+	return p.code
+}
+
+// GetConfig returns provider config
+func (p Provider) GetConfig() model.ProviderConfig {
+	return p.config
+}
+
+// GetHistoricalRates returns consensus of underlying providers historical rates
+func (p Provider) GetHistoricalRates(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
+	// Validate request
+	if _, err := p.IsRequestValid(serviceRequest); err != nil {
+		return model.RatesResponse{}, customerror.NewUnprocessableError("request is invalid. " + err.Error())
+	}
+	return p.aggregate(serviceRequest, func(request model.RatesRequest) (model.RatesResponse, error) {
+		serviceResponse, _, err := p.pipeline.GetHistorical(&request)
+		return serviceResponse, err
+	})
+}
+
+// GetLatestRates returns consensus of underlying providers latest rates
+func (p Provider) GetLatestRates(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
+	// Validate request
+	if _, err := p.IsRequestValid(serviceRequest); err != nil {
+		return model.RatesResponse{}, customerror.NewUnprocessableError("request is invalid. " + err.Error())
+	}
+	return p.aggregate(serviceRequest, func(request model.RatesRequest) (model.RatesResponse, error) {
+		serviceResponse, _, err := p.pipeline.GetLatest(&request)
+		return serviceResponse, err
+	})
+}
+
+// PreloadRates does nothing, underlying providers preload their own rates
+func (p Provider) PreloadRates(date time.Time, save bool) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
+	return make(map[string]decimal.Decimal), make(map[string]decimal.Decimal), time.Time{}, nil
+}
+
+// GetRateGenerationTime returns historical rates generated time on provider side
+func (p Provider) GetRateGenerationTime() time.Time {
+	return p.BaseProvider.GetRateGenerationTime(p.config.RatesGeneratedTime)
+}
+
+// GetSupportedCurrencies returns configured currencies or union of underlying providers currencies
+func (p Provider) GetSupportedCurrencies() []string {
+	return p.AggregatingProvider.GetSupportedCurrencies(p)
+}
+
+// IsRequestValid validates API call to provider.
+func (p Provider) IsRequestValid(ratesRequest model.RatesRequest) (bool, error) {
+	return p.BaseProvider.IsRequestValid(p, ratesRequest)
+}
+
+// GetCapabilities returns provider capabilities, rate types are published by any of underlying providers
+func (p Provider) GetCapabilities() provider.Capabilities {
+	return p.AggregatingProvider.GetCapabilities(p)
+}
+
+// GetPrecision returns number of decimal places of provider's rates
+func (p Provider) GetPrecision() int32 {
+	return p.BaseProvider.GetPrecision(p)
+}
+
+// GetLocation returns location for current provider
+func (p Provider) GetLocation() *time.Location {
+	location, err := time.LoadLocation(p.config.Location)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// BuildEntity builds entity with given rates
+func (p Provider) BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate decimal.Decimal, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate {
+	e := p.BaseProvider.BuildEntity(endpoint, p.GetCode(), baseCurrency, quotedCurrency, rate, rateDate, providerDate)
+	return e
+}
+
+// aggregate queries all underlying providers concurrently and builds consensus rate for every symbol
+func (p Provider) aggregate(
+	serviceRequest model.RatesRequest,
+	fetch func(request model.RatesRequest) (model.RatesResponse, error)) (model.RatesResponse, error) {
+	var (
+		failures        []string
+		isUnavailable   bool // at least one provider failed because of outage, not because of request
+		serviceResponse = model.RatesResponse{
+			Rates:   make(map[string]decimal.Decimal),
+			Sources: make(map[string][]string),
+		}
+	)
+
+	responses := p.fetchSources(serviceRequest, fetch)
+	for _, source := range responses {
+		if source.err != nil {
+			failures = append(failures, source.code+": "+source.err.Error())
+			isUnavailable = isUnavailable || customerror.IsRetryable(source.err)
+		}
+	}
+
+	for _, symbol := range serviceRequest.Symbols {
+		rate, sources := p.getConsensusRate(responses, symbol)
+		if len(sources) == 0 {
+			message := "no one provider has rate for " + symbol + ". " + strings.Join(failures, "; ")
+			if isUnavailable {
+				return model.RatesResponse{}, customerror.NewUnavailableError(message)
+			}
+			return model.RatesResponse{}, customerror.NewUnprocessableError(message)
+		}
+		serviceResponse.Rates[symbol] = rate
+		serviceResponse.Sources[symbol] = sources
+	}
+
+	// The latest of contributing providers generated time
+	for _, source := range responses {
+		if source.err == nil && source.response.Timestamp > serviceResponse.Timestamp {
+			serviceResponse.Timestamp = source.response.Timestamp
+		}
+	}
+	return serviceResponse, nil
+}
+
+// fetchSources requests rates from all configured underlying providers in parallel. Every underlying provider
+// is requested through pipeline, so its rates are cached under its own key and shared with direct requests
+func (p Provider) fetchSources(
+	serviceRequest model.RatesRequest,
+	fetch func(request model.RatesRequest) (model.RatesResponse, error)) []sourceResponse {
+	var (
+		wg        sync.WaitGroup
+		responses = make([]sourceResponse, len(p.config.Providers))
+	)
+	for i, code := range p.config.Providers {
+		wg.Add(1)
+		go func(i int, code string) {
+			defer wg.Done()
+			responses[i] = sourceResponse{code: code}
+			prov, err := p.registry.GetProvider(code)
+			if err != nil {
+				responses[i].err = err
+				return
+			}
+			// Underlying provider does not support such request (currency, date etc.)
+			if _, err = prov.IsRequestValid(serviceRequest); err != nil {
+				responses[i].err = err
+				return
+			}
+			responses[i].response, responses[i].err = fetch(p.GetRequest(serviceRequest, prov))
+		}(i, code)
+	}
+	wg.Wait()
+	return responses
+}
+
+// getConsensusRate returns median of rates for symbol without outliers and codes of contributing providers
+func (p Provider) getConsensusRate(responses []sourceResponse, symbol string) (decimal.Decimal, []string) {
+	var (
+		rates    []decimal.Decimal
+		sources  []string
+		accepted []decimal.Decimal
+	)
+	for _, source := range responses {
+		if source.err != nil {
+			continue
+		}
+		if rate, ok := source.response.Rates[symbol]; ok && !rate.IsZero() {
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) == 0 {
+		return decimal.Zero, nil
+	}
+
+	// Drop rates deviating from median beyond tolerance, if there are enough sources to detect outlier
+	tolerance := p.config.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	median := p.median(rates)
+	for _, source := range responses {
+		if source.err != nil {
+			continue
+		}
+		rate, ok := source.response.Rates[symbol]
+		if !ok || rate.IsZero() {
+			continue
+		}
+		if len(rates) < MinOutlierSources || rate.Sub(median).Abs().Div(median).LessThanOrEqual(decimal.NewFromFloat(tolerance)) {
+			accepted = append(accepted, rate)
+			sources = append(sources, source.code)
+		}
+	}
+	if len(accepted) == 0 {
+		return decimal.Zero, nil
+	}
+	return p.median(accepted).Round(p.GetPrecision()), sources
+}
+
+// median returns median value of passed rates
+func (p Provider) median(rates []decimal.Decimal) decimal.Decimal {
+	sorted := make([]decimal.Decimal, len(rates))
+	copy(sorted, rates)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].LessThan(sorted[j])
+	})
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return sorted[middle-1].Add(sorted[middle]).Div(decimal.NewFromInt(2))
+	}
+	return sorted[middle]
+}
@@ -5,18 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/PuerkitoBio/goquery"
+	"github.com/netandreus/go-forex-rates/internal/pkg/anomaly"
 	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
 	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
 	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
 	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
-	"io/ioutil"
-	"log"
-	"math"
-	"net/http"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -24,6 +22,9 @@ import (
 // Code emirates provider code
 const Code = "emirates"
 
+// PivotCurrency all emirates rates are quoted against this currency
+const PivotCurrency = "AED"
+
 // ApiResponse is Emirates service http api response
 type ApiResponse struct {
 	// HTML table in json field
@@ -36,18 +37,30 @@ type ApiResponse struct {
 // Provider implements emirates provider structure
 type Provider struct {
 	provider.BaseProvider
-	code   string
-	db     *gorm.DB
-	config model.ProviderConfig
+	code         string
+	db           *gorm.DB
+	config       model.ProviderConfig
+	availability *provider.Availability
+	detector     *anomaly.Detector
+	rates        *repository.RateRepository
 }
 
 // New constructor
-func New(db *gorm.DB, config *model.ApplicationConfig) *Provider {
+func New(
+	db *gorm.DB,
+	config *model.ApplicationConfig,
+	availability *provider.Availability,
+	detector *anomaly.Detector,
+	rates *repository.RateRepository) *Provider {
 	// Build provider
 	provider := &Provider{
-		code:   Code,
-		db:     db,
-		config: config.Providers[Code],
+		BaseProvider: provider.NewBaseProvider(availability),
+		code:         Code,
+		db:           db,
+		config:       config.Providers[Code],
+		availability: availability,
+		detector:     detector,
+		rates:        rates,
 	}
 	return provider
 }
@@ -65,7 +78,7 @@ func (p Provider) GetConfig() model.ProviderConfig {
 // GetHistoricalRates searches for rates in internal DB, fetch from provider API if needed and saves to internal DB
 func (p Provider) GetHistoricalRates(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
 	var (
-		directRates, reverseRates map[string]float64
+		directRates, reverseRates map[string]decimal.Decimal
 		providerGeneratedTime     time.Time
 		err                       error
 	)
@@ -79,48 +92,44 @@ func (p Provider) GetHistoricalRates(serviceRequest model.RatesRequest) (model.R
 	// Validate request
 	_, err = p.IsRequestValid(serviceRequest)
 	if err != nil {
-		return model.RatesResponse{}, errors.New("request is invalid. " + err.Error())
+		return model.RatesResponse{}, customerror.NewUnprocessableError("request is invalid. " + err.Error())
 	}
 
 	// Fetch (all rates for date) and save if not
-	today := util.GetToday(p.GetLocation())
+	today := p.availability.GetToday(p)
 	dateObject, _ := time.ParseInLocation(util.DateFormatEu, date, p.GetLocation())
-	save := !util.IsDateEquals(dateObject, today) && !dateObject.After(today) && !force && !serviceRequest.IsForwarded
-	directRates, reverseRates, providerGeneratedTime, err = p.PreloadRates(dateObject, save)
+	save := serviceRequest.Date.Before(today) && !force && !serviceRequest.IsForwarded
+	if directRates, reverseRates, providerGeneratedTime, err = p.PreloadRates(dateObject, save); err != nil {
+		return model.RatesResponse{}, err
+	}
 
 	// Filter by symbols
 	serviceResponse := model.RatesResponse{}
-	if baseCurrency == "AED" {
+	if baseCurrency == PivotCurrency {
 		serviceResponse.Rates = p.filterRates(directRates, baseCurrency, symbols)
 		serviceResponse.Timestamp = providerGeneratedTime.Unix()
 		return serviceResponse, nil
-	} else if len(symbols) == 1 && symbols[0] == "AED" {
-		serviceResponse.Rates = map[string]float64{symbols[0]: reverseRates[baseCurrency]}
+	} else if len(symbols) == 1 && symbols[0] == PivotCurrency {
+		serviceResponse.Rates = map[string]decimal.Decimal{symbols[0]: reverseRates[baseCurrency]}
 		serviceResponse.Timestamp = providerGeneratedTime.Unix()
 		return serviceResponse, nil
 	} else {
-		serviceResponse.Rates = make(map[string]float64)
+		serviceResponse.Rates = make(map[string]decimal.Decimal)
 		serviceResponse.Timestamp = providerGeneratedTime.Unix()
-		return serviceResponse, customerror.NewBadRequestError("base currency should be AED, or symbols should be [AED]")
+		return serviceResponse, customerror.NewUnprocessableError("base currency should be AED, or symbols should be [AED]")
 	}
 }
 
-// GetLatestRates provides yesterday-defined rate for today latest rate if time < 23:00
+// GetLatestRates provides the latest published rate (yesterday-defined rate for today latest rate if time < 23:00)
 func (p Provider) GetLatestRates(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
-	var now = time.Now()
-	rateGenerationTime := p.GetRateGenerationTime()
-	if now.Hour() >= rateGenerationTime.Hour() && now.Minute() > rateGenerationTime.Minute() && now.Second() > rateGenerationTime.Second() {
-		serviceRequest.Date = util.GetToday(time.UTC)
-	} else {
-		serviceRequest.Date = util.GetYesterday(time.UTC)
-	}
+	serviceRequest.Date = p.availability.GetLatestDate(p)
 	serviceRequest.Force = false
 	serviceRequest.IsForwarded = true
 	return p.GetHistoricalRates(serviceRequest)
 }
 
 // PreloadRates fetch (all rates for date) and save if not
-func (p Provider) PreloadRates(dateObject time.Time, save bool) (map[string]float64, map[string]float64, time.Time, error) {
+func (p Provider) PreloadRates(dateObject time.Time, save bool) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
 	directRates, reverseRates, providerGeneratedTime, err := p.fetchHistoricalRatesAllSymbols(dateObject.Format(util.DateFormatEu))
 	if err != nil {
 		return nil, nil, time.Time{}, err
@@ -128,23 +137,46 @@ func (p Provider) PreloadRates(dateObject time.Time, save bool) (map[string]floa
 
 	// Save fetched rates to database
 	if save {
-		p.saveHistoricalRatesAllSymbols("AED", directRates, reverseRates, dateObject, providerGeneratedTime)
+		// Sanity checks, suspicious rates are quarantined instead of saving and serving until they are reviewed
+		anomalies := p.detector.Approve(anomaly.Rates{
+			Provider:              p.GetCode(),
+			BaseCurrency:          PivotCurrency,
+			RateType:              util.RateTypeMid,
+			Date:                  dateObject,
+			ProviderGeneratedTime: providerGeneratedTime,
+			Direct:                directRates,
+			Reverse:               reverseRates,
+			Expected:              p.GetSupportedCurrencies(),
+			Precision:             p.GetPrecision(),
+		})
+		if len(anomalies) > 0 {
+			return nil, nil, time.Time{}, customerror.NewUnprocessableError("rates of provider " + p.GetCode() + " for " +
+				dateObject.Format(util.DateFormatEu) + " are quarantined pending review. " + strings.Join(anomalies, "; "))
+		}
+		if err = p.saveHistoricalRatesAllSymbols(PivotCurrency, directRates, reverseRates, dateObject, providerGeneratedTime); err != nil {
+			return nil, nil, time.Time{}, err
+		}
 	}
 	return directRates, reverseRates, providerGeneratedTime, nil
 }
 
 // IsRequestValid validates API call to provider.
 func (p Provider) IsRequestValid(ratesRequest model.RatesRequest) (bool, error) {
-	// BaseProvider API call request validation
-	if _, err := p.BaseProvider.IsRequestValid(p, ratesRequest); err != nil {
-		return false, err
-	}
+	return p.BaseProvider.IsRequestValid(p, ratesRequest)
+}
 
-	// Provider request validation. Check AED is in baseCurrency OR ONLY AED in symbols
-	if !(ratesRequest.BaseCurrency == "AED" || (len(ratesRequest.Symbols) == 1 && ratesRequest.Symbols[0] == "AED")) {
-		return false, errors.New("provider needs AED is in baseCurrency OR ONLY AED in symbols")
-	}
-	return true, nil
+// GetCapabilities returns provider capabilities: end-of-day rates against AED
+func (p Provider) GetCapabilities() provider.Capabilities {
+	capabilities := p.BaseProvider.GetCapabilities(p)
+	capabilities.PivotCurrency = PivotCurrency
+	capabilities.LatestMode = provider.LatestModeEndOfDay
+	capabilities.Preload = true
+	return capabilities
+}
+
+// GetPrecision returns number of decimal places of provider's rates
+func (p Provider) GetPrecision() int32 {
+	return p.BaseProvider.GetPrecision(p)
 }
 
 // GetRateGenerationTime returns historical rates generated time on provider side
@@ -153,7 +185,7 @@ func (p Provider) GetRateGenerationTime() time.Time {
 }
 
 // BuildEntity builds entity with given rates
-func (p Provider) BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate float64, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate {
+func (p Provider) BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate decimal.Decimal, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate {
 	e := p.BaseProvider.BuildEntity(endpoint, p.GetCode(), baseCurrency, quotedCurrency, rate, rateDate, providerDate)
 	return e
 }
@@ -170,11 +202,11 @@ func (p Provider) GetSupportedCurrencies() []string {
 }
 
 // filterRates filter all fetched rates by symbols passed in arguments
-func (p Provider) filterRates(rates map[string]float64, baseCurrency string, symbols []string) map[string]float64 {
-	var filteredRates = make(map[string]float64)
+func (p Provider) filterRates(rates map[string]decimal.Decimal, baseCurrency string, symbols []string) map[string]decimal.Decimal {
+	var filteredRates = make(map[string]decimal.Decimal)
 	for _, symbol := range symbols {
 		if symbol == baseCurrency {
-			filteredRates[symbol] = 1
+			filteredRates[symbol] = decimal.NewFromInt(1)
 		} else {
 			filteredRates[symbol] = rates[symbol]
 		}
@@ -182,22 +214,18 @@ func (p Provider) filterRates(rates map[string]float64, baseCurrency string, sym
 	return filteredRates
 }
 
-// fetchHistoricalRatesAllSymbols - fetches directRates and reverseRates. Return normalized (scale=6) rates
-func (p Provider) fetchHistoricalRatesAllSymbols(date string) (map[string]float64, map[string]float64, time.Time, error) {
+// fetchHistoricalRatesAllSymbols - fetches directRates and reverseRates. Return normalized (scale=precision) rates
+func (p Provider) fetchHistoricalRatesAllSymbols(date string) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
 	var (
-		directRates  map[string]float64
-		reverseRates map[string]float64
+		directRates  map[string]decimal.Decimal
+		reverseRates map[string]decimal.Decimal
 		providerDate time.Time
 		err          error
-		resp         *http.Response
 		body         []byte
 	)
 
 	url := "https://www." + "centralbank" + ".ae" + "/en/fx-rates-ajax?date=" + date + "&v=2"
-	if resp, err = http.Get(url); err != nil {
-		return nil, nil, time.Time{}, err
-	}
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+	if body, err = p.Fetch(p, url); err != nil {
 		return nil, nil, time.Time{}, err
 	}
 	if directRates, reverseRates, providerDate, err = p.getRatesFromResponse(body); err != nil {
@@ -206,60 +234,68 @@ func (p Provider) fetchHistoricalRatesAllSymbols(date string) (map[string]float6
 	return directRates, reverseRates, providerDate, nil
 }
 
-// saveHistoricalRatesAllSymbols save all history currency rates for one day
+// saveHistoricalRatesAllSymbols save all history currency rates for one day.
+// Already stored rates, which were changed by provider, are revised
 func (p Provider) saveHistoricalRatesAllSymbols(
 	baseCurrency string,
-	directRates map[string]float64,
-	reverseRates map[string]float64,
+	directRates map[string]decimal.Decimal,
+	reverseRates map[string]decimal.Decimal,
 	date time.Time,
 	providerDate time.Time) error {
-	var e *entity.CurrencyRate
+	var entities []*entity.CurrencyRate
 
-	// Save direct rates
+	// Direct rates
 	for quotedCurrency, directRate := range directRates {
-		e = p.BuildEntity(util.EndpointHistorical, baseCurrency, quotedCurrency, directRate, date, providerDate)
-		p.saveEntity(e)
+		entities = append(entities, p.BuildEntity(util.EndpointHistorical, baseCurrency, quotedCurrency, directRate, date, providerDate))
 	}
 
-	// Save reverse rates
+	// Reverse rates
 	for quotedCurrency, reverseRate := range reverseRates {
-		e = p.BuildEntity(util.EndpointHistorical, quotedCurrency, baseCurrency, reverseRate, date, providerDate)
-		p.saveEntity(e)
+		entities = append(entities, p.BuildEntity(util.EndpointHistorical, quotedCurrency, baseCurrency, reverseRate, date, providerDate))
 	}
-	return nil
-}
 
-// saveEntity saves single pair currency rate entity to database
-func (p Provider) saveEntity(entity *entity.CurrencyRate) *gorm.DB {
-	// OnConflict is needed if there is forward Latest to History(yesterday) endpoint and rates already loaded
-	return p.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entity)
+	// Save
+	var revised []string
+	for _, e := range entities {
+		isRevised, err := p.rates.Save(e)
+		if err != nil {
+			return err
+		}
+		if isRevised {
+			revised = append(revised, e.BaseCurrency+e.QuotedCurrency)
+		}
+	}
+	if len(revised) > 0 {
+		logger.LogWarning("Rates of provider "+p.GetCode()+" for "+date.Format(util.DateFormatEu)+
+			" are revised: "+strings.Join(revised, ", "), "REVISION")
+	}
+	return nil
 }
 
 // getRatesFromResponse parse response and get fetch rates from it
-func (p Provider) getRatesFromResponse(body []byte) (map[string]float64, map[string]float64, time.Time, error) {
+func (p Provider) getRatesFromResponse(body []byte) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
 	var (
 		err                    error
 		apiJson                ApiResponse
 		table                  string
-		directRates            = make(map[string]float64)
-		reverseRates           = make(map[string]float64)
-		normalizedDirectRates  = make(map[string]float64)
-		normalizedReverseRates = make(map[string]float64)
+		directRates            = make(map[string]decimal.Decimal)
+		reverseRates           = make(map[string]decimal.Decimal)
+		normalizedDirectRates  = make(map[string]decimal.Decimal)
+		normalizedReverseRates = make(map[string]decimal.Decimal)
 		providerGeneratedTime  time.Time
 		doc                    *goquery.Document
-		currencyRate           float64
+		currencyRate           decimal.Decimal
 		currencyCode           string
 	)
 	if err = json.Unmarshal(body, &apiJson); err != nil {
-		return directRates, reverseRates, time.Time{}, err
+		return directRates, reverseRates, time.Time{}, customerror.NewProviderError("invalid provider " + p.GetCode() + " response. " + err.Error())
 	}
 	table = apiJson.Table
 
 	// Load the HTML document
 	reader := strings.NewReader(table)
 	if doc, err = goquery.NewDocumentFromReader(reader); err != nil {
-		log.Fatal(err)
-		return directRates, reverseRates, time.Time{}, err
+		return directRates, reverseRates, time.Time{}, customerror.NewProviderError("invalid provider " + p.GetCode() + " rates table. " + err.Error())
 	}
 
 	// Find rates
@@ -268,7 +304,7 @@ func (p Provider) getRatesFromResponse(body []byte) (map[string]float64, map[str
 		tds := s.Find("td")
 		currencyName := tds.Eq(0).Text()
 		currencyRateStr := tds.Eq(1).Text()
-		if currencyRate, err = strconv.ParseFloat(currencyRateStr, 64); err != nil {
+		if currencyRate, err = decimal.NewFromString(strings.TrimSpace(currencyRateStr)); err != nil {
 			return
 		}
 		if currencyCode, err = p.getCurrencyCodeByName(currencyName); err != nil {
@@ -288,14 +324,20 @@ func (p Provider) getRatesFromResponse(body []byte) (map[string]float64, map[str
 	}
 
 	// Centralbank.ae returns reverse rates, need convert to direct
+	precision := p.GetPrecision()
 	for cur, reverseRate := range reverseRates {
-		normalizedReverseRate := math.Round(reverseRate*1000000) / 1000000
-		normalizedReverseRates[cur] = normalizedReverseRate
-		normalizedDirectRates[cur] = math.Round((1/normalizedReverseRate)*1000000) / 1000000
+		if reverseRate.IsZero() {
+			continue
+		}
+		normalizedReverseRates[cur] = reverseRate.Round(precision)
+		normalizedDirectRates[cur] = decimal.NewFromInt(1).DivRound(reverseRate, precision)
 	}
 
 	// Can not parse date from rates provider
-	return normalizedDirectRates, normalizedReverseRates, providerGeneratedTime, err
+	if err != nil {
+		return normalizedDirectRates, normalizedReverseRates, providerGeneratedTime, customerror.NewProviderError("invalid provider " + p.GetCode() + " last updated date. " + err.Error())
+	}
+	return normalizedDirectRates, normalizedReverseRates, providerGeneratedTime, nil
 }
 
 // getDateFormats returns provider-related date formats
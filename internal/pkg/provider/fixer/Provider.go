@@ -3,15 +3,13 @@ package fixer
 
 import (
 	"encoding/json"
-	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
 	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
 	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
 	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
-	"io/ioutil"
-	"math"
-	"net/http"
 	"strings"
 	"time"
 )
@@ -19,6 +17,14 @@ import (
 // Code fixer provider code
 const Code = "fixer"
 
+// ApiResponse is fixer.io API response
+type ApiResponse struct {
+	model.SuccessApiResponse
+
+	// Error code and message, if request failed
+	Error model.ApiError `json:"error"`
+}
+
 // Provider implements fixer provider structure
 type Provider struct {
 	provider.BaseProvider
@@ -28,12 +34,13 @@ type Provider struct {
 }
 
 // New constructor
-func New(db *gorm.DB, config *model.ApplicationConfig) *Provider {
+func New(db *gorm.DB, config *model.ApplicationConfig, availability *provider.Availability) *Provider {
 	// Build provider
 	provider := &Provider{
-		code:   Code,
-		db:     db,
-		config: config.Providers[Code],
+		BaseProvider: provider.NewBaseProvider(availability),
+		code:         Code,
+		db:           db,
+		config:       config.Providers[Code],
 	}
 	return provider
 }
@@ -42,15 +49,14 @@ func New(db *gorm.DB, config *model.ApplicationConfig) *Provider {
 func (p Provider) GetHistoricalRates(serviceRequest model.RatesRequest) (model.RatesResponse, error) {
 	var (
 		err                   error
-		rates                 map[string]float64
+		rates                 map[string]decimal.Decimal
 		providerGeneratedTime time.Time
-		resp                  *http.Response
 		body                  []byte
 		serviceResponse       model.RatesResponse
 	)
 	// Validate request
 	if _, err := p.IsRequestValid(serviceRequest); err != nil {
-		return model.RatesResponse{}, errors.New("request is invalid. " + err.Error())
+		return model.RatesResponse{}, customerror.NewUnprocessableError("request is invalid. " + err.Error())
 	}
 
 	// Fetch rates
@@ -58,10 +64,7 @@ func (p Provider) GetHistoricalRates(serviceRequest model.RatesRequest) (model.R
 	symbolsStr := strings.Join(serviceRequest.Symbols, ",")
 	dateStr := serviceRequest.Date.Format(util.DateFormatEu)
 	url := "https://data.fixer.io/api/" + dateStr + "?access_key=" + apiKey + "&base=" + serviceRequest.BaseCurrency + "&symbols=" + symbolsStr
-	if resp, err = http.Get(url); err != nil {
-		return serviceResponse, err
-	}
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+	if body, err = p.Fetch(p, url); err != nil {
 		return serviceResponse, err
 	}
 	if rates, _, providerGeneratedTime, err = p.getRatesFromResponse(body); err != nil {
@@ -81,25 +84,21 @@ func (p Provider) GetLatestRates(serviceRequest model.RatesRequest) (model.Rates
 	var (
 		serviceResponse       model.RatesResponse
 		err                   error
-		rates                 map[string]float64
+		rates                 map[string]decimal.Decimal
 		providerGeneratedTime time.Time
-		resp                  *http.Response
 		body                  []byte
 	)
 
 	// Validate request
 	if _, err = p.IsRequestValid(serviceRequest); err != nil {
-		return serviceResponse, errors.New("request is invalid. " + err.Error())
+		return serviceResponse, customerror.NewUnprocessableError("request is invalid. " + err.Error())
 	}
 
 	// Load rates from fixer.io
 	apiKey := p.config.APIKey
 	symbolsStr := strings.Join(serviceRequest.Symbols, ",")
 	url := "https://data.fixer.io/api/latest?access_key=" + apiKey + "&base=" + serviceRequest.BaseCurrency + "&symbols=" + symbolsStr
-	if resp, err = http.Get(url); err != nil {
-		return serviceResponse, err
-	}
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+	if body, err = p.Fetch(p, url); err != nil {
 		return serviceResponse, err
 	}
 	if rates, _, providerGeneratedTime, err = p.getRatesFromResponse(body); err != nil {
@@ -113,8 +112,8 @@ func (p Provider) GetLatestRates(serviceRequest model.RatesRequest) (model.Rates
 }
 
 // PreloadRates preload all available rates for given date
-func (p Provider) PreloadRates(date time.Time, save bool) (map[string]float64, map[string]float64, time.Time, error) {
-	return make(map[string]float64), make(map[string]float64), time.Time{}, nil
+func (p Provider) PreloadRates(date time.Time, save bool) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
+	return make(map[string]decimal.Decimal), make(map[string]decimal.Decimal), time.Time{}, nil
 }
 
 // GetRateGenerationTime returns historical rates generated time on provider side
@@ -137,8 +136,18 @@ func (p Provider) IsRequestValid(ratesRequest model.RatesRequest) (bool, error)
 	return p.BaseProvider.IsRequestValid(p, ratesRequest)
 }
 
+// GetCapabilities returns provider capabilities
+func (p Provider) GetCapabilities() provider.Capabilities {
+	return p.BaseProvider.GetCapabilities(p)
+}
+
+// GetPrecision returns number of decimal places of provider's rates
+func (p Provider) GetPrecision() int32 {
+	return p.BaseProvider.GetPrecision(p)
+}
+
 // BuildEntity builds entity with given rates
-func (p Provider) BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate float64, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate {
+func (p Provider) BuildEntity(endpoint string, baseCurrency string, quotedCurrency string, rate decimal.Decimal, rateDate time.Time, providerDate time.Time) *entity.CurrencyRate {
 	e := p.BaseProvider.BuildEntity(endpoint, p.GetCode(), baseCurrency, quotedCurrency, rate, rateDate, providerDate)
 	return e
 }
@@ -155,23 +164,26 @@ func (p Provider) GetSupportedCurrencies() []string {
 }
 
 // getRatesFromResponse parse response and get fetch rates from it
-func (p Provider) getRatesFromResponse(body []byte) (map[string]float64, map[string]float64, time.Time, error) {
+func (p Provider) getRatesFromResponse(body []byte) (map[string]decimal.Decimal, map[string]decimal.Decimal, time.Time, error) {
 	var (
 		err                    error
-		apiJson                model.SuccessApiResponse
-		directRates            = make(map[string]float64)
-		reverseRates           = make(map[string]float64)
-		normalizedDirectRates  = make(map[string]float64)
-		normalizedReverseRates = make(map[string]float64)
+		apiJson                ApiResponse
+		directRates            = make(map[string]decimal.Decimal)
+		reverseRates           = make(map[string]decimal.Decimal)
+		normalizedDirectRates  = make(map[string]decimal.Decimal)
+		normalizedReverseRates = make(map[string]decimal.Decimal)
 		providerGeneratedTime  time.Time
 	)
 	// Rates
 	if err = json.Unmarshal(body, &apiJson); err != nil {
-		return directRates, reverseRates, time.Time{}, err
+		return directRates, reverseRates, time.Time{}, customerror.NewProviderError("invalid provider " + p.GetCode() + " response. " + err.Error())
+	}
+	if !apiJson.Success {
+		return directRates, reverseRates, time.Time{}, customerror.NewProviderError("provider " + p.GetCode() + " request failed. " + apiJson.Error.Info)
 	}
 
 	for cur, directRate := range apiJson.Rates {
-		normalizedDirectRates[cur] = math.Round(directRate*1000000) / 1000000
+		normalizedDirectRates[cur] = directRate.Round(p.GetPrecision())
 	}
 
 	// Provider generated time
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ApiKeyRepository stores API access keys and their usage counters
+type ApiKeyRepository struct {
+	db *gorm.DB
+}
+
+// BuildApiKeyRepository /* *ApiKeyRepository
+func BuildApiKeyRepository(db *gorm.DB) (*ApiKeyRepository, error) {
+	return NewApiKeyRepository(db), nil
+}
+
+// NewApiKeyRepository constructor
+func NewApiKeyRepository(db *gorm.DB) *ApiKeyRepository {
+	return &ApiKeyRepository{
+		db: db,
+	}
+}
+
+// FindByAccessKey returns enabled key, nil if key is not found
+func (r *ApiKeyRepository) FindByAccessKey(accessKey string) (*entity.ApiKey, error) {
+	key := &entity.ApiKey{}
+	err := r.db.Where("access_key = ? AND enabled = ?", accessKey, true).Take(key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, customerror.NewDatabaseError(err.Error())
+	}
+	return key, nil
+}
+
+// AddUsage increments number of key owner's requests in month
+func (r *ApiKeyRepository) AddUsage(name string, month string, requests int) error {
+	usage := &entity.ApiKeyUsage{
+		Name:        name,
+		Month:       month,
+		Requests:    requests,
+		UpdatedTime: time.Now().UTC(),
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":     gorm.Expr("requests + ?", requests),
+			"updated_time": usage.UpdatedTime,
+		}),
+	}).Create(usage).Error
+	if err != nil {
+		return customerror.NewDatabaseError(err.Error())
+	}
+	return nil
+}
+
+// FindUsage returns usage counters of all key owners in month
+func (r *ApiKeyRepository) FindUsage(month string) ([]entity.ApiKeyUsage, error) {
+	var usage []entity.ApiKeyUsage
+	if err := r.db.Where("month = ?", month).Order("name").Find(&usage).Error; err != nil {
+		return usage, customerror.NewDatabaseError(err.Error())
+	}
+	return usage, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"gorm.io/gorm"
+)
+
+// AuditRepository stores audit log of changes made via Admin API
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// BuildAuditRepository /* *AuditRepository
+func BuildAuditRepository(db *gorm.DB) (*AuditRepository, error) {
+	return NewAuditRepository(db), nil
+}
+
+// NewAuditRepository constructor
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// Create saves audit log record
+func (r *AuditRepository) Create(record *entity.AuditLog) error {
+	if err := r.db.Create(record).Error; err != nil {
+		return customerror.NewDatabaseError(err.Error())
+	}
+	return nil
+}
+
+// Save updates audit log record
+func (r *AuditRepository) Save(record *entity.AuditLog) error {
+	if err := r.db.Save(record).Error; err != nil {
+		return customerror.NewDatabaseError(err.Error())
+	}
+	return nil
+}
+
+// FindAll returns the latest audit log records, optionally of one provider
+func (r *AuditRepository) FindAll(providerCode string, limit int) ([]entity.AuditLog, error) {
+	var records []entity.AuditLog
+	query := r.db.Order("id DESC").Limit(limit)
+	if providerCode != "" {
+		query = query.Where("provider = ?", providerCode)
+	}
+	if err := query.Find(&records).Error; err != nil {
+		return records, customerror.NewDatabaseError(err.Error())
+	}
+	return records, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// AverageRepository calculates statistics of stored historical rates over accounting periods
+// and stores them for closed periods
+type AverageRepository struct {
+	db *gorm.DB
+}
+
+// BuildAverageRepository /* *AverageRepository
+func BuildAverageRepository(db *gorm.DB) (*AverageRepository, error) {
+	return NewAverageRepository(db), nil
+}
+
+// NewAverageRepository constructor
+func NewAverageRepository(db *gorm.DB) *AverageRepository {
+	return &AverageRepository{
+		db: db,
+	}
+}
+
+// FindStored returns stored statistics of requested currencies, absent ones are skipped
+func (r *AverageRepository) FindStored(averageRequest model.AverageRequest) (map[string]model.AverageRate, error) {
+	var (
+		entities []entity.CurrencyRateAverage
+		rates    = make(map[string]model.AverageRate)
+	)
+	result := r.db.
+		Where("provider = ?", averageRequest.ProviderCode).
+		Where("base_currency = ?", averageRequest.BaseCurrency).
+		Where("quoted_currency IN (?)", averageRequest.Symbols).
+		Where("rate_type = ?", averageRequest.RateType).
+		Where("period = ?", averageRequest.Period.Name).
+		Where("publication_days = ?", averageRequest.PublicationDays).
+		Find(&entities)
+	if result.Error != nil {
+		return rates, customerror.NewDatabaseError(result.Error.Error())
+	}
+	for _, e := range entities {
+		rates[e.QuotedCurrency] = model.AverageRate{
+			Mean:  e.Mean,
+			Min:   e.Min,
+			Max:   e.Max,
+			Count: e.Count,
+		}
+	}
+	return rates, nil
+}
+
+// Calculate calculates statistics of stored historical rates of requested currencies over period.
+// Only rates of dates accepted by isObservationDay are used, mean is rounded to precision
+func (r *AverageRepository) Calculate(
+	averageRequest model.AverageRequest,
+	symbols []string,
+	isObservationDay func(date time.Time) bool,
+	precision int32) (map[string]model.AverageRate, error) {
+	var (
+		entities []entity.CurrencyRate
+		sums     = make(map[string]decimal.Decimal)
+		rates    = make(map[string]model.AverageRate)
+	)
+	if len(symbols) == 0 {
+		return rates, nil
+	}
+	result := r.db.Model(&entity.CurrencyRate{}).
+		Select([]string{"quoted_currency", "value", "rate_date"}).
+		Where("base_currency = ?", averageRequest.BaseCurrency).
+		Where("quoted_currency IN (?)", symbols).
+		Where("endpoint = ?", util.EndpointHistorical).
+		Where("provider = ?", averageRequest.ProviderCode).
+		Where("rate_type = ?", averageRequest.RateType).
+		Where("rate_date BETWEEN ? AND ?",
+			averageRequest.Period.Start.Format(util.DateFormatEu),
+			averageRequest.Period.End.Format(util.DateFormatEu)).
+		Find(&entities)
+	if result.Error != nil {
+		return rates, customerror.NewDatabaseError(result.Error.Error())
+	}
+	for _, e := range entities {
+		date, err := time.ParseInLocation(util.DateFormatEu, normalizeRateDate(e.RateDate), time.UTC)
+		if err != nil || !isObservationDay(date) {
+			continue
+		}
+		rate, ok := rates[e.QuotedCurrency]
+		if !ok {
+			rate = model.AverageRate{Min: e.Value, Max: e.Value}
+		}
+		if e.Value.LessThan(rate.Min) {
+			rate.Min = e.Value
+		}
+		if e.Value.GreaterThan(rate.Max) {
+			rate.Max = e.Value
+		}
+		rate.Count++
+		rates[e.QuotedCurrency] = rate
+		sums[e.QuotedCurrency] = sums[e.QuotedCurrency].Add(e.Value)
+	}
+	for currency, rate := range rates {
+		rate.Mean = sums[currency].DivRound(decimal.NewFromInt(int64(rate.Count)), precision)
+		rates[currency] = rate
+	}
+	return rates, nil
+}
+
+// Save stores statistics of closed period
+func (r *AverageRepository) Save(averageRequest model.AverageRequest, rates map[string]model.AverageRate) error {
+	for currency, rate := range rates {
+		e := &entity.CurrencyRateAverage{
+			Provider:        averageRequest.ProviderCode,
+			BaseCurrency:    averageRequest.BaseCurrency,
+			QuotedCurrency:  currency,
+			RateType:        averageRequest.RateType,
+			Period:          averageRequest.Period.Name,
+			PublicationDays: averageRequest.PublicationDays,
+			Mean:            rate.Mean,
+			Min:             rate.Min,
+			Max:             rate.Max,
+			Count:           rate.Count,
+			CalculatedTime:  time.Now().UTC(),
+		}
+		// OnConflict is need for On duplicate key cause.
+		if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(e).Error; err != nil {
+			return customerror.NewDatabaseError(err.Error())
+		}
+	}
+	return nil
+}
+
+// DeleteByFilter deletes stored statistics of provider's periods containing dates of filter (all periods
+// if date range is not closed), they are calculated again on the next request. Returns number of deleted rows
+func (r *AverageRepository) DeleteByFilter(filter model.RatesFilter) (int64, error) {
+	query := r.db.Where("provider = ?", filter.ProviderCode)
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() {
+		query = query.Where("period IN (?)", model.GetPeriodNames(filter.StartDate, filter.EndDate))
+	}
+	if filter.RateType != "" {
+		query = query.Where("rate_type = ?", filter.RateType)
+	}
+	result := query.Delete(&entity.CurrencyRateAverage{})
+	if result.Error != nil {
+		return 0, customerror.NewDatabaseError(result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// JobRepository stores preload jobs and state of their dates
+type JobRepository struct {
+	db *gorm.DB
+}
+
+// BuildJobRepository /* *JobRepository
+func BuildJobRepository(db *gorm.DB) (*JobRepository, error) {
+	return NewJobRepository(db), nil
+}
+
+// NewJobRepository constructor
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+// Create saves new job with its dates
+func (r *JobRepository) Create(job *entity.PreloadJob) error {
+	if err := r.db.Create(job).Error; err != nil {
+		return customerror.NewDatabaseError(err.Error())
+	}
+	return nil
+}
+
+// FindAll returns the latest jobs without dates
+func (r *JobRepository) FindAll(limit int) ([]entity.PreloadJob, error) {
+	var jobs []entity.PreloadJob
+	if err := r.db.Order("id DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		return jobs, customerror.NewDatabaseError(err.Error())
+	}
+	for i := range jobs {
+		r.normalizeDates(&jobs[i])
+	}
+	return jobs, nil
+}
+
+// Find returns job with its dates
+func (r *JobRepository) Find(id uint) (*entity.PreloadJob, error) {
+	job := &entity.PreloadJob{}
+	err := r.db.Preload("Dates").Take(job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, customerror.NewNotFoundError("job " + strconv.Itoa(int(id)) + " is not found")
+	}
+	if err != nil {
+		return nil, customerror.NewDatabaseError(err.Error())
+	}
+	r.normalizeDates(job)
+	return job, nil
+}
+
+// FindUnfinished returns pending and running jobs with their dates
+func (r *JobRepository) FindUnfinished() ([]entity.PreloadJob, error) {
+	var jobs []entity.PreloadJob
+	err := r.db.Preload("Dates").
+		Where("status IN (?)", []string{entity.JobStatusPending, entity.JobStatusRunning}).
+		Order("id").
+		Find(&jobs).Error
+	if err != nil {
+		return jobs, customerror.NewDatabaseError(err.Error())
+	}
+	for i := range jobs {
+		r.normalizeDates(&jobs[i])
+	}
+	return jobs, nil
+}
+
+// CountDates returns number of job's dates per status
+func (r *JobRepository) CountDates(jobID uint) (map[string]int, error) {
+	var (
+		rows []struct {
+			Status string
+			Dates  int
+		}
+		counts = make(map[string]int)
+	)
+	err := r.db.Model(&entity.PreloadJobDate{}).
+		Select("status, COUNT(*) AS dates").
+		Where("job_id = ?", jobID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return counts, customerror.NewDatabaseError(err.Error())
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Dates
+	}
+	return counts, nil
+}
+
+// SaveJob saves job state without dates
+func (r *JobRepository) SaveJob(job *entity.PreloadJob) error {
+	if err := r.db.Omit("Dates").Save(job).Error; err != nil {
+		return customerror.NewDatabaseError(err.Error())
+	}
+	return nil
+}
+
+// SaveDate saves job date state
+func (r *JobRepository) SaveDate(date *entity.PreloadJobDate) error {
+	if err := r.db.Save(date).Error; err != nil {
+		return customerror.NewDatabaseError(err.Error())
+	}
+	return nil
+}
+
+// ResetFailedDates marks failed dates of job as pending again. Returns number of reset dates
+func (r *JobRepository) ResetFailedDates(jobID uint, updatedTime time.Time) (int64, error) {
+	result := r.db.Model(&entity.PreloadJobDate{}).
+		Where("job_id = ?", jobID).
+		Where("status = ?", entity.JobDateStatusFailed).
+		Updates(map[string]interface{}{
+			"status":       entity.JobDateStatusPending,
+			"error":        "",
+			"updated_time": updatedTime,
+		})
+	if result.Error != nil {
+		return 0, customerror.NewDatabaseError(result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
+
+// normalizeDates cuts time part of job dates, loaded from database as date time
+func (r *JobRepository) normalizeDates(job *entity.PreloadJob) {
+	job.StartDate = normalizeRateDate(job.StartDate)
+	job.EndDate = normalizeRateDate(job.EndDate)
+	for i := range job.Dates {
+		job.Dates[i].RateDate = normalizeRateDate(job.Dates[i].RateDate)
+	}
+}
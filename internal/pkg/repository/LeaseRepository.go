@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaseRepository stores leases shared by application instances.
+// Expiration is calculated by database clock, so clocks of instances may differ
+type LeaseRepository struct {
+	db *gorm.DB
+}
+
+// BuildLeaseRepository /* *LeaseRepository
+func BuildLeaseRepository(db *gorm.DB) (*LeaseRepository, error) {
+	return NewLeaseRepository(db), nil
+}
+
+// NewLeaseRepository constructor
+func NewLeaseRepository(db *gorm.DB) *LeaseRepository {
+	return &LeaseRepository{
+		db: db,
+	}
+}
+
+// Acquire takes free or expired lease, or prolongs lease held by holder, for ttl seconds.
+// Returns true if holder holds the lease
+func (r *LeaseRepository) Acquire(name string, holder string, ttl int) (bool, error) {
+	// Create lease row if not exists
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.Lease{Name: name}).Error
+	if err != nil {
+		return false, customerror.NewDatabaseError(err.Error())
+	}
+	result := r.db.Model(&entity.Lease{}).
+		Where("name = ?", name).
+		Where("holder = ? OR expires_time IS NULL OR expires_time < UTC_TIMESTAMP()", holder).
+		Updates(map[string]interface{}{
+			"holder":       holder,
+			"expires_time": gorm.Expr("UTC_TIMESTAMP() + INTERVAL ? SECOND", ttl),
+		})
+	if result.Error != nil {
+		return false, customerror.NewDatabaseError(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Release frees lease held by holder
+func (r *LeaseRepository) Release(name string, holder string) error {
+	err := r.db.Model(&entity.Lease{}).
+		Where("name = ? AND holder = ?", name, holder).
+		Update("expires_time", nil).Error
+	if err != nil {
+		return customerror.NewDatabaseError(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// QuarantineRepository stores rates, which failed ingestion-time sanity checks
+type QuarantineRepository struct {
+	db *gorm.DB
+}
+
+// BuildQuarantineRepository /* *QuarantineRepository
+func BuildQuarantineRepository(db *gorm.DB) (*QuarantineRepository, error) {
+	return NewQuarantineRepository(db), nil
+}
+
+// NewQuarantineRepository constructor
+func NewQuarantineRepository(db *gorm.DB) *QuarantineRepository {
+	return &QuarantineRepository{
+		db: db,
+	}
+}
+
+// Save stores quarantined rates. Rates already quarantined for the same date are skipped
+func (r *QuarantineRepository) Save(entities []*entity.QuarantinedRate) error {
+	for _, e := range entities {
+		// OnConflict is need for On duplicate key cause.
+		if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(e).Error; err != nil {
+			return customerror.NewDatabaseError(err.Error())
+		}
+	}
+	return nil
+}
+
+// Exists returns true if rates of provider for date with passed base currency are quarantined
+func (r *QuarantineRepository) Exists(providerCode string, baseCurrency string, rateType string, date time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.QuarantinedRate{}).
+		Where("provider = ?", providerCode).
+		Where("base_currency = ?", baseCurrency).
+		Where("rate_type = ?", rateType).
+		Where("rate_date = ?", date.Format(util.DateFormatEu)).
+		Count(&count).Error
+	if err != nil {
+		return false, customerror.NewDatabaseError(err.Error())
+	}
+	return count > 0, nil
+}
+
+// FindAll returns quarantined rates of provider ordered by date
+func (r *QuarantineRepository) FindAll(providerCode string) ([]entity.QuarantinedRate, error) {
+	var entities []entity.QuarantinedRate
+	err := r.db.
+		Where("provider = ?", providerCode).
+		Order("rate_date, base_currency, quoted_currency").
+		Find(&entities).Error
+	if err != nil {
+		return entities, customerror.NewDatabaseError(err.Error())
+	}
+	for i := range entities {
+		entities[i].RateDate = normalizeRateDate(entities[i].RateDate)
+	}
+	return entities, nil
+}
+
+// FindByDate returns quarantined rates of provider for date
+func (r *QuarantineRepository) FindByDate(providerCode string, date time.Time) ([]entity.QuarantinedRate, error) {
+	var entities []entity.QuarantinedRate
+	err := r.db.
+		Where("provider = ?", providerCode).
+		Where("rate_date = ?", date.Format(util.DateFormatEu)).
+		Find(&entities).Error
+	if err != nil {
+		return entities, customerror.NewDatabaseError(err.Error())
+	}
+	return entities, nil
+}
+
+// Delete deletes quarantined rates of provider for date. Returns number of deleted pairs
+func (r *QuarantineRepository) Delete(providerCode string, date time.Time) (int64, error) {
+	result := r.db.
+		Where("provider = ?", providerCode).
+		Where("rate_date = ?", date.Format(util.DateFormatEu)).
+		Delete(&entity.QuarantinedRate{})
+	if result.Error != nil {
+		return 0, customerror.NewDatabaseError(result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
+
+// FindDates returns quarantined dates of provider (format YYYY-MM-DD) in date range
+func (r *QuarantineRepository) FindDates(providerCode string, start time.Time, end time.Time) (map[string]bool, error) {
+	var (
+		rateDates []string
+		dates     = make(map[string]bool)
+	)
+	err := r.db.Model(&entity.QuarantinedRate{}).
+		Distinct("rate_date").
+		Where("provider = ?", providerCode).
+		Where("rate_date BETWEEN ? AND ?", start.Format(util.DateFormatEu), end.Format(util.DateFormatEu)).
+		Pluck("rate_date", &rateDates).Error
+	if err != nil {
+		return dates, customerror.NewDatabaseError(err.Error())
+	}
+	for _, rateDate := range rateDates {
+		dates[normalizeRateDate(rateDate)] = true
+	}
+	return dates, nil
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// RateRepository reads stored historical rates
+type RateRepository struct {
+	db *gorm.DB
+}
+
+// BuildRateRepository /* *RateRepository
+func BuildRateRepository(db *gorm.DB) (*RateRepository, error) {
+	return NewRateRepository(db), nil
+}
+
+// NewRateRepository constructor
+func NewRateRepository(db *gorm.DB) *RateRepository {
+	return &RateRepository{
+		db: db,
+	}
+}
+
+// FindPrevious returns the closest stored historical rates before date, not earlier than window days before it
+func (r *RateRepository) FindPrevious(
+	providerCode string,
+	baseCurrency string,
+	symbols []string,
+	rateType string,
+	date time.Time,
+	window int) (map[string]decimal.Decimal, error) {
+	var (
+		entities []entity.CurrencyRate
+		dates    = make(map[string]string)
+		rates    = make(map[string]decimal.Decimal)
+	)
+	if len(symbols) == 0 {
+		return rates, nil
+	}
+	result := r.db.Model(&entity.CurrencyRate{}).
+		Select([]string{"quoted_currency", "value", "rate_date"}).
+		Where("base_currency = ?", baseCurrency).
+		Where("quoted_currency IN (?)", symbols).
+		Where("endpoint = ?", util.EndpointHistorical).
+		Where("provider = ?", providerCode).
+		Where("rate_type = ?", rateType).
+		Where("rate_date BETWEEN ? AND ?",
+			date.AddDate(0, 0, -window).Format(util.DateFormatEu),
+			date.AddDate(0, 0, -1).Format(util.DateFormatEu)).
+		Find(&entities)
+	if result.Error != nil {
+		return rates, customerror.NewDatabaseError(result.Error.Error())
+	}
+	for _, e := range entities {
+		rateDate := normalizeRateDate(e.RateDate)
+		if rateDate > dates[e.QuotedCurrency] {
+			dates[e.QuotedCurrency] = rateDate
+			rates[e.QuotedCurrency] = e.Value
+		}
+	}
+	return rates, nil
+}
+
+// CountByDate returns number of stored historical pairs of provider per date (format YYYY-MM-DD) in date range.
+// If base currency is passed, only pairs with this base currency are counted
+func (r *RateRepository) CountByDate(providerCode string, baseCurrency string, start time.Time, end time.Time) (map[string]int, error) {
+	var (
+		rows []struct {
+			RateDate string
+			Pairs    int
+		}
+		counts = make(map[string]int)
+	)
+	query := r.db.Model(&entity.CurrencyRate{}).
+		Select("rate_date, COUNT(*) AS pairs").
+		Where("endpoint = ?", util.EndpointHistorical).
+		Where("provider = ?", providerCode).
+		Where("rate_type = ?", util.RateTypeMid).
+		Where("rate_date BETWEEN ? AND ?", start.Format(util.DateFormatEu), end.Format(util.DateFormatEu))
+	if baseCurrency != "" {
+		query = query.Where("base_currency = ?", baseCurrency)
+	}
+	if err := query.Group("rate_date").Scan(&rows).Error; err != nil {
+		return counts, customerror.NewDatabaseError(err.Error())
+	}
+	for _, row := range rows {
+		counts[normalizeRateDate(row.RateDate)] = row.Pairs
+	}
+	return counts, nil
+}
+
+// FindLatestDate returns the latest date of stored historical rates of provider (format YYYY-MM-DD),
+// empty string if there are no stored rates
+func (r *RateRepository) FindLatestDate(providerCode string) (string, error) {
+	var latest *string
+	row := r.db.Model(&entity.CurrencyRate{}).
+		Select("MAX(rate_date)").
+		Where("endpoint = ?", util.EndpointHistorical).
+		Where("provider = ?", providerCode).
+		Row()
+	if err := row.Scan(&latest); err != nil {
+		return "", customerror.NewDatabaseError(err.Error())
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return normalizeRateDate(*latest), nil
+}
+
+// Save stores historical rate. If rate of the pair for the date is already stored with other value,
+// stored rate is moved to revisions and replaced by passed one. Stored rate is locked till it is replaced,
+// so concurrent saves of the same pair are serialized. Returns true if stored rate is revised
+func (r *RateRepository) Save(e *entity.CurrencyRate) (bool, error) {
+	var revised bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		stored, err := r.findForUpdate(tx, e)
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			// OnConflict is need for On duplicate key cause, if the pair is saved concurrently
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(e)
+			if result.Error != nil || result.RowsAffected > 0 {
+				return result.Error
+			}
+			if stored, err = r.findForUpdate(tx, e); err != nil || stored == nil {
+				return err
+			}
+		}
+		if stored.Value.Equal(e.Value) {
+			return nil
+		}
+
+		// Keep prior version and replace it
+		if err = tx.Create(newRevision(stored, e.RequestTime)).Error; err != nil {
+			return err
+		}
+		revised = true
+		return tx.Model(stored).Updates(map[string]interface{}{
+			"value":                   e.Value,
+			"provider_generated_time": e.ProviderGeneratedTime,
+			"request_time":            e.RequestTime,
+		}).Error
+	})
+	if err != nil {
+		return false, customerror.NewDatabaseError(err.Error())
+	}
+	return revised, nil
+}
+
+// Delete deletes stored historical rates selected by filter, they are moved to revisions known until deleted time.
+// Returns number of deleted pairs
+func (r *RateRepository) Delete(filter model.RatesFilter, deletedTime time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var (
+			entities []entity.CurrencyRate
+			ids      []uint
+		)
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("endpoint = ?", util.EndpointHistorical).
+			Where("provider = ?", filter.ProviderCode)
+		if !filter.StartDate.IsZero() {
+			query = query.Where("rate_date >= ?", filter.StartDate.Format(util.DateFormatEu))
+		}
+		if !filter.EndDate.IsZero() {
+			query = query.Where("rate_date <= ?", filter.EndDate.Format(util.DateFormatEu))
+		}
+		if filter.BaseCurrency != "" {
+			query = query.Where("base_currency = ?", filter.BaseCurrency)
+		}
+		if filter.QuotedCurrency != "" {
+			query = query.Where("quoted_currency = ?", filter.QuotedCurrency)
+		}
+		if filter.RateType != "" {
+			query = query.Where("rate_type = ?", filter.RateType)
+		}
+		if err := query.Find(&entities).Error; err != nil {
+			return err
+		}
+		if len(entities) == 0 {
+			return nil
+		}
+
+		// Keep deleted versions
+		revisions := make([]*entity.CurrencyRateRevision, 0, len(entities))
+		for i := range entities {
+			revisions = append(revisions, newRevision(&entities[i], deletedTime))
+			ids = append(ids, entities[i].ID)
+		}
+		if err := tx.Create(&revisions).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id IN (?)", ids).Delete(&entity.CurrencyRate{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, customerror.NewDatabaseError(err.Error())
+	}
+	return deleted, nil
+}
+
+// findForUpdate returns stored rate of the same pair, date and rate type as passed one and locks it
+// till the end of transaction, nil if rate is not stored
+func (r *RateRepository) findForUpdate(tx *gorm.DB, e *entity.CurrencyRate) (*entity.CurrencyRate, error) {
+	var entities []entity.CurrencyRate
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("base_currency = ?", e.BaseCurrency).
+		Where("quoted_currency = ?", e.QuotedCurrency).
+		Where("endpoint = ?", e.Endpoint).
+		Where("provider = ?", e.Provider).
+		Where("rate_type = ?", e.RateType).
+		Where("rate_date = ?", normalizeRateDate(e.RateDate)).
+		Limit(1).
+		Find(&entities).Error
+	if err != nil || len(entities) == 0 {
+		return nil, err
+	}
+	return &entities[0], nil
+}
+
+// newRevision returns prior version of stored rate, which was known until passed time
+func newRevision(stored *entity.CurrencyRate, knownUntil time.Time) *entity.CurrencyRateRevision {
+	return &entity.CurrencyRateRevision{
+		Provider:              stored.Provider,
+		BaseCurrency:          stored.BaseCurrency,
+		QuotedCurrency:        stored.QuotedCurrency,
+		Value:                 stored.Value,
+		RateType:              stored.RateType,
+		RateDate:              normalizeRateDate(stored.RateDate),
+		ProviderGeneratedTime: stored.ProviderGeneratedTime,
+		KnownFromTime:         stored.RequestTime,
+		KnownUntilTime:        knownUntil,
+	}
+}
+
+// FindKnownAt returns historical rates of the date as they were known at the instant: stored rates known since
+// the instant or earlier, otherwise their revisions, which were actual at the instant.
+// Rates, which were not known at the instant, are absent in result. Also returns provider generated time of rates
+func (r *RateRepository) FindKnownAt(
+	providerCode string,
+	baseCurrency string,
+	symbols []string,
+	rateType string,
+	date time.Time,
+	knownAt time.Time) (map[string]decimal.Decimal, time.Time, error) {
+	var (
+		entities              []entity.CurrencyRate
+		revisions             []entity.CurrencyRateRevision
+		rates                 = make(map[string]decimal.Decimal)
+		providerGeneratedTime time.Time
+	)
+	if len(symbols) == 0 {
+		return rates, providerGeneratedTime, nil
+	}
+	dateStr := date.Format(util.DateFormatEu)
+	err := r.db.
+		Where("base_currency = ?", baseCurrency).
+		Where("quoted_currency IN (?)", symbols).
+		Where("endpoint = ?", util.EndpointHistorical).
+		Where("provider = ?", providerCode).
+		Where("rate_type = ?", rateType).
+		Where("rate_date = ?", dateStr).
+		Where("request_time <= ?", knownAt).
+		Find(&entities).Error
+	if err != nil {
+		return rates, providerGeneratedTime, customerror.NewDatabaseError(err.Error())
+	}
+	for _, e := range entities {
+		rates[e.QuotedCurrency] = e.Value
+		providerGeneratedTime = e.ProviderGeneratedTime
+	}
+	err = r.db.
+		Where("base_currency = ?", baseCurrency).
+		Where("quoted_currency IN (?)", symbols).
+		Where("provider = ?", providerCode).
+		Where("rate_type = ?", rateType).
+		Where("rate_date = ?", dateStr).
+		Where("known_from_time <= ? AND known_until_time > ?", knownAt, knownAt).
+		Find(&revisions).Error
+	if err != nil {
+		return rates, providerGeneratedTime, customerror.NewDatabaseError(err.Error())
+	}
+	for _, revision := range revisions {
+		rates[revision.QuotedCurrency] = revision.Value
+		providerGeneratedTime = revision.ProviderGeneratedTime
+	}
+	return rates, providerGeneratedTime, nil
+}
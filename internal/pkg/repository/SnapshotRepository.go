@@ -0,0 +1,119 @@
+// Package repository contains database repositories of application entities
+package repository
+
+import (
+	"errors"
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/entity"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"time"
+)
+
+// insertSnapshotSql inserts snapshot unless the latest snapshot of the same pair has the same rate and date.
+// Comparison with the latest row is done by database, so it is consistent across instances and after restart
+const insertSnapshotSql = `INSERT INTO currency_rate_snapshot
+    (provider, base_currency, quoted_currency, value, rate_type, rate_date, provider_generated_time, fetched_time)
+SELECT ?, ?, ?, ?, ?, ?, ?, ? FROM DUAL
+WHERE NOT EXISTS (
+    SELECT 1 FROM (
+        SELECT value, rate_date FROM currency_rate_snapshot
+        WHERE provider = ? AND base_currency = ? AND quoted_currency = ? AND rate_type = ?
+        ORDER BY fetched_time DESC
+        LIMIT 1
+    ) AS last
+    WHERE last.value = CAST(? AS DECIMAL(30,12)) AND last.rate_date = ?
+)`
+
+// SnapshotRepository stores every distinct latest rate fetched from providers
+type SnapshotRepository struct {
+	db *gorm.DB
+}
+
+// BuildSnapshotRepository /* *SnapshotRepository
+func BuildSnapshotRepository(db *gorm.DB) (*SnapshotRepository, error) {
+	return NewSnapshotRepository(db), nil
+}
+
+// NewSnapshotRepository constructor
+func NewSnapshotRepository(db *gorm.DB) *SnapshotRepository {
+	return &SnapshotRepository{
+		db: db,
+	}
+}
+
+// Record saves latest rates fetched from provider at fetchedTime. Rates not changed since the previous snapshot are skipped
+func (r *SnapshotRepository) Record(serviceRequest model.RatesRequest, serviceResponse model.RatesResponse, fetchedTime time.Time) error {
+	rateDate := serviceRequest.Date.Format(util.DateFormatEu)
+	for quotedCurrency, rate := range serviceResponse.Rates {
+		if quotedCurrency == serviceRequest.BaseCurrency {
+			continue
+		}
+		snapshot := entity.CurrencyRateSnapshot{
+			Provider:              serviceRequest.ProviderCode,
+			BaseCurrency:          serviceRequest.BaseCurrency,
+			QuotedCurrency:        quotedCurrency,
+			Value:                 rate,
+			RateType:              serviceRequest.GetRateType(),
+			RateDate:              rateDate,
+			ProviderGeneratedTime: time.Unix(serviceResponse.Timestamp, 0).UTC(),
+			FetchedTime:           fetchedTime.UTC(),
+		}
+		err := r.db.Exec(insertSnapshotSql,
+			snapshot.Provider, snapshot.BaseCurrency, snapshot.QuotedCurrency, snapshot.Value, snapshot.RateType,
+			snapshot.RateDate, snapshot.ProviderGeneratedTime, snapshot.FetchedTime,
+			snapshot.Provider, snapshot.BaseCurrency, snapshot.QuotedCurrency, snapshot.RateType,
+			snapshot.Value, snapshot.RateDate).Error
+		if err != nil {
+			return customerror.NewDatabaseError(err.Error())
+		}
+	}
+	return nil
+}
+
+// FindAt returns latest rates, which were served at passed instant, and the oldest date of provider's publication
+// they belong to
+func (r *SnapshotRepository) FindAt(serviceRequest model.RatesRequest, at time.Time) (model.RatesResponse, time.Time, error) {
+	var (
+		rateDate        time.Time
+		serviceResponse = model.RatesResponse{
+			Rates: make(map[string]decimal.Decimal),
+		}
+	)
+	for _, quotedCurrency := range serviceRequest.Symbols {
+		if quotedCurrency == serviceRequest.BaseCurrency {
+			serviceResponse.Rates[quotedCurrency] = decimal.NewFromInt(1)
+			continue
+		}
+		snapshot := entity.CurrencyRateSnapshot{}
+		err := r.db.
+			Where("provider = ?", serviceRequest.ProviderCode).
+			Where("base_currency = ?", serviceRequest.BaseCurrency).
+			Where("quoted_currency = ?", quotedCurrency).
+			Where("rate_type = ?", serviceRequest.GetRateType()).
+			Where("fetched_time <= ?", at.UTC()).
+			Order("fetched_time DESC").
+			Take(&snapshot).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return serviceResponse, rateDate, customerror.NewNotFoundError("no latest rate " + serviceRequest.BaseCurrency + "/" + quotedCurrency +
+				" was served by provider " + serviceRequest.ProviderCode + " at " + at.Format(time.RFC3339))
+		}
+		if err != nil {
+			return serviceResponse, rateDate, customerror.NewDatabaseError(err.Error())
+		}
+		serviceResponse.Rates[quotedCurrency] = snapshot.Value
+		if snapshot.ProviderGeneratedTime.Unix() > serviceResponse.Timestamp {
+			serviceResponse.Timestamp = snapshot.ProviderGeneratedTime.Unix()
+		}
+		date, err := time.ParseInLocation(util.DateFormatEu, normalizeRateDate(snapshot.RateDate), time.UTC)
+		if err != nil {
+			return serviceResponse, rateDate, err
+		}
+		if rateDate.IsZero() || date.Before(rateDate) {
+			rateDate = date
+		}
+	}
+	return serviceResponse, rateDate, nil
+}
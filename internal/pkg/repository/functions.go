@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/netandreus/go-forex-rates/internal/pkg/util"
+
+// normalizeRateDate cuts time part of rate date, loaded from database as date time (format YYYY-MM-DD)
+func normalizeRateDate(rateDate string) string {
+	if len(rateDate) > len(util.DateFormatEu) {
+		return rateDate[:len(util.DateFormatEu)]
+	}
+	return rateDate
+}
@@ -3,18 +3,32 @@ package service
 import (
 	"github.com/eko/gocache/cache"
 	"github.com/eko/gocache/store"
+	"github.com/netandreus/go-forex-rates/internal/pkg/anomaly"
 	cache_store "github.com/netandreus/go-forex-rates/internal/pkg/cache/store"
-	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
 	gocache "github.com/patrickmn/go-cache"
 	"gorm.io/gorm"
 	"time"
 )
 
+// BuildL1Cache /* *gocache.Cache
+func BuildL1Cache() (*gocache.Cache, error) {
+	return gocache.New(1*time.Second, 1*time.Second), nil // 600 sec for production
+}
+
+// BuildL2Cache /* *cache_store.MySQLStore
+func BuildL2Cache(
+	mysqlClient *gorm.DB,
+	detector *anomaly.Detector,
+	rates *repository.RateRepository,
+	clock util.Clock) (*cache_store.MySQLStore, error) {
+	return cache_store.NewMySQLStore(mysqlClient, detector, rates, clock, nil), nil
+}
+
 // BuildCache /* *cache.ChainCache
-func BuildCache(config *model.ApplicationConfig, mysqlClient *gorm.DB) (*cache.ChainCache, error) {
-	gocacheClient := gocache.New(1*time.Second, 1*time.Second) // 600 sec for production
+func BuildCache(gocacheClient *gocache.Cache, mysqlStore *cache_store.MySQLStore) (*cache.ChainCache, error) {
 	gocacheStore := store.NewGoCache(gocacheClient, nil)
-	mysqlStore := cache_store.NewMySQLStore(mysqlClient, nil)
 	// Initialize chained cache
 	cacheManager := cache.NewChain(
 		cache.New(gocacheStore),
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+)
+
+// BuildClock /* util.Clock
+func BuildClock() (util.Clock, error) {
+	return util.SystemClock{}, nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/grpcapi"
+	"github.com/netandreus/go-forex-rates/internal/pkg/grpcapi/ratespb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// BuildGrpc /* *grpc.Server
+func BuildGrpc(ratesServer *grpcapi.RatesServer) (*grpc.Server, error) {
+	server := grpc.NewServer()
+	ratespb.RegisterRatesServiceServer(server, ratesServer)
+
+	// Server reflection, lets clients (grpcurl etc.) discover services without proto file
+	reflection.Register(server)
+	return server, nil
+}
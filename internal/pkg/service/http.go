@@ -1,20 +1,31 @@
 package service
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/netandreus/go-forex-rates/internal/pkg/auth"
 	"github.com/netandreus/go-forex-rates/internal/pkg/controller"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"net/http"
+	"time"
 )
 
 // BuildHttp /* *gin.Engine
-func BuildHttp(apiController *controller.ApiController, config *model.ApplicationConfig) (*gin.Engine, error) {
+func BuildHttp(
+	apiController *controller.ApiController,
+	adminController *controller.AdminController,
+	correctionController *controller.CorrectionController,
+	streamController *controller.StreamController,
+	authenticator *auth.Authenticator,
+	config *model.ApplicationConfig) (*gin.Engine, error) {
 	// Settings
 	gin.SetMode(config.Engine.Mode)
 
-	r := gin.Default()
+	// Default gin engine, API access keys passed in query string are not written to access log
+	r := gin.New()
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: logFormatter}), gin.Recovery())
 	// Home page
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -37,13 +48,80 @@ func BuildHttp(apiController *controller.ApiController, config *model.Applicatio
 
 		// Health check
 		v1.GET("/status", apiController.Status())
+	}
 
+	// Rates API, requires API access key if auth is enabled
+	rates := v1.Group("", authenticator.Handler())
+	{
 		// Historical endpoint
-		v1.GET("/historical/:provider/:date", apiController.Historical())
+		rates.GET("/historical/:provider/:date", apiController.Historical())
+
+		// Historical endpoint, rates valid at instant
+		rates.GET("/historical/:provider", apiController.HistoricalAt())
 
 		// Latest endpoint
-		v1.GET("/latest/:provider", apiController.Latest())
+		rates.GET("/latest/:provider", apiController.Latest())
+
+		// Average rates over accounting period endpoint
+		rates.GET("/average/:provider/:period", apiController.Average())
+
+		// Served latest rates snapshot endpoint
+		rates.GET("/snapshot/:provider", apiController.Snapshot())
+
+		// Latest rates changes streaming endpoint (Server-Sent Events)
+		rates.GET("/stream/:provider", streamController.Latest())
+	}
+
+	// Admin API, requires API access key allowed to access Admin API if auth is enabled
+	admin := v1.Group("/admin", authenticator.AdminHandler())
+	{
+		// Gaps in stored historical rates
+		admin.GET("/gaps/:provider", adminController.Gaps())
+		admin.POST("/gaps/:provider/repair", adminController.RepairGaps())
+
+		// Preload jobs
+		admin.GET("/jobs", adminController.Jobs())
+		admin.GET("/jobs/:id", adminController.Job())
+		admin.POST("/jobs", adminController.CreateJob())
+		admin.POST("/jobs/:id/retry", adminController.RetryJob())
+
+		// Usage counters of API access keys
+		admin.GET("/usage", adminController.Usage())
+
+		// Cached rates invalidation and correction
+		admin.DELETE("/cache/:provider", correctionController.PurgeCache())
+		admin.DELETE("/rates/:provider", correctionController.DeleteRates())
+		admin.PUT("/rates/:provider/:date", correctionController.OverwriteRates())
+		admin.POST("/rates/:provider/:date/refetch", correctionController.RefetchRates())
+		admin.GET("/audit", correctionController.Audit())
+
+		// Review of rates quarantined by sanity checks
+		admin.GET("/quarantine/:provider", correctionController.Quarantine())
+		admin.POST("/quarantine/:provider/:date/release", correctionController.ReleaseQuarantine())
+		admin.DELETE("/quarantine/:provider/:date", correctionController.DiscardQuarantine())
 	}
 
 	return r, nil
 }
+
+// logFormatter is gin default access log format with API access key redacted from request URI
+func logFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency - param.Latency%time.Second
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		auth.RedactAccessKey(param.Path),
+		param.ErrorMessage,
+	)
+}
@@ -0,0 +1,228 @@
+// Package stream pushes changes of latest rates to subscribers of streaming APIs
+package stream
+
+import (
+	"github.com/netandreus/go-forex-rates/internal/pkg/customerror"
+	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
+	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/pipeline"
+	"github.com/netandreus/go-forex-rates/internal/pkg/util"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subscriptionBuffer is number of updates queued for subscriber, slower subscriber is unsubscribed
+const subscriptionBuffer = 16
+
+// Update is latest rates pushed to subscriber. The first update contains all subscribed rates,
+// the next ones - only rates changed since the previous update, or all rates if their date is changed
+type Update struct {
+	// Request resolved by pipeline (date, endpoint)
+	Request model.RatesRequest
+
+	// Changed rates
+	Response model.RatesResponse
+
+	// All subscribed rates
+	Latest model.RatesResponse
+}
+
+// Subscription is subscriber of group of the same latest rates
+type Subscription struct {
+	hub     *Hub
+	group   *group
+	updates chan Update
+}
+
+// Updates returns channel of updates. Channel is closed if subscriber is too slow to receive them
+func (s *Subscription) Updates() <-chan Update {
+	return s.updates
+}
+
+// Close unsubscribes subscriber, polling of group stops after the last subscriber is gone
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// group is subscribers of the same provider, base currency, symbols and rate type, rates are polled once per group
+type group struct {
+	key         string
+	request     model.RatesRequest
+	last        *Update
+	subscribers map[*Subscription]bool
+	stop        chan struct{}
+}
+
+// Hub polls latest rates of every subscription group through pipeline (and its cache) and pushes changed rates
+// to subscribers
+type Hub struct {
+	config   *model.ApplicationConfig
+	pipeline *pipeline.Pipeline
+
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+// BuildHub /* *Hub
+func BuildHub(config *model.ApplicationConfig, pipeline *pipeline.Pipeline) (*Hub, error) {
+	return NewHub(config, pipeline), nil
+}
+
+// NewHub is the constructor
+func NewHub(config *model.ApplicationConfig, pipeline *pipeline.Pipeline) *Hub {
+	if config.Grpc.StreamInterval > 0 {
+		logger.LogWarning("grpc.stream_interval is deprecated, use stream.interval", "CONFIG")
+	}
+	return &Hub{
+		config:   config,
+		pipeline: pipeline,
+		groups:   make(map[string]*group),
+	}
+}
+
+// Subscribe subscribes to changes of latest rates of passed request. Invalid request is rejected,
+// provider failures are not: rates are pushed as soon as provider is available
+func (h *Hub) Subscribe(serviceRequest model.RatesRequest) (*Subscription, error) {
+	// Streaming is always served through cache
+	serviceRequest.Endpoint = util.EndpointLatest
+	serviceRequest.Force = false
+	current, err := h.fetch(serviceRequest)
+	if err != nil && !customerror.IsRetryable(err) {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := h.getGroupKey(serviceRequest)
+	g, ok := h.groups[key]
+	if !ok {
+		g = &group{
+			key:         key,
+			request:     serviceRequest,
+			last:        current,
+			subscribers: make(map[*Subscription]bool),
+			stop:        make(chan struct{}),
+		}
+		h.groups[key] = g
+		go h.poll(g)
+	}
+	subscription := &Subscription{
+		hub:     h,
+		group:   g,
+		updates: make(chan Update, subscriptionBuffer),
+	}
+	g.subscribers[subscription] = true
+
+	// The first update contains all rates known to group
+	if g.last != nil {
+		subscription.updates <- *g.last
+	}
+	return subscription, nil
+}
+
+// GetInterval returns interval of latest rates polling, the same as L1 cache expiration by default.
+// Deprecated grpc.stream_interval is used, if stream.interval is not set
+func (h *Hub) GetInterval() time.Duration {
+	if h.config.Stream.Interval > 0 {
+		return time.Duration(h.config.Stream.Interval) * time.Second
+	}
+	if h.config.Grpc.StreamInterval > 0 {
+		return time.Duration(h.config.Grpc.StreamInterval) * time.Second
+	}
+	if maxAge := h.pipeline.GetLatestMaxAge(); maxAge > 0 {
+		return maxAge
+	}
+	return time.Second
+}
+
+// unsubscribe removes subscriber from its group and stops polling of empty group
+func (h *Hub) unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(subscription)
+}
+
+// remove removes subscriber from its group, must be called with locked mutex
+func (h *Hub) remove(subscription *Subscription) {
+	g := subscription.group
+	if !g.subscribers[subscription] {
+		return
+	}
+	delete(g.subscribers, subscription)
+	close(subscription.updates)
+	if len(g.subscribers) == 0 {
+		delete(h.groups, g.key)
+		close(g.stop)
+	}
+}
+
+// poll polls latest rates of group until the last subscriber is gone
+func (h *Hub) poll(g *group) {
+	ticker := time.NewTicker(h.GetInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+		}
+		current, err := h.fetch(g.request)
+		if err != nil {
+			logger.LogWarning("Latest rates of provider \""+g.request.ProviderCode+"\" are not streamed. "+err.Error(), "STREAM")
+			continue
+		}
+		h.publish(g, current)
+	}
+}
+
+// publish pushes rates changed since the last update to subscribers of group. All rates are pushed,
+// if date of rates is changed (provider published rates of the next day)
+func (h *Hub) publish(g *group, current *Update) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	changed := *current
+	if g.last != nil && util.IsDateEquals(g.last.Request.Date, current.Request.Date) {
+		changed.Response.Rates = make(map[string]decimal.Decimal)
+		for currency, rate := range current.Response.Rates {
+			if lastRate, ok := g.last.Response.Rates[currency]; !ok || !lastRate.Equal(rate) {
+				changed.Response.Rates[currency] = rate
+			}
+		}
+	}
+	g.last = current
+	if len(changed.Response.Rates) == 0 {
+		return
+	}
+	for subscription := range g.subscribers {
+		select {
+		case subscription.updates <- changed:
+		default:
+			logger.LogWarning("Subscriber of provider \""+g.request.ProviderCode+"\" is too slow, unsubscribed", "STREAM")
+			h.remove(subscription)
+		}
+	}
+}
+
+// fetch requests latest rates through pipeline
+func (h *Hub) fetch(serviceRequest model.RatesRequest) (*Update, error) {
+	serviceResponse, _, err := h.pipeline.GetLatest(&serviceRequest)
+	if err != nil {
+		return nil, err
+	}
+	return &Update{Request: serviceRequest, Response: serviceResponse, Latest: serviceResponse}, nil
+}
+
+// getGroupKey returns key of subscription group, the same rates are polled once for all subscribers
+func (h *Hub) getGroupKey(serviceRequest model.RatesRequest) string {
+	symbols := append([]string{}, serviceRequest.Symbols...)
+	sort.Strings(symbols)
+	return strings.Join([]string{
+		serviceRequest.ProviderCode,
+		serviceRequest.BaseCurrency,
+		strings.Join(symbols, ","),
+		serviceRequest.GetRateType(),
+	}, ":")
+}
@@ -0,0 +1,50 @@
+package util
+
+import "time"
+
+// Clock provides current time. Use it instead of time.Now() to be able to replace time source
+type Clock interface {
+	// Now returns current time
+	Now() time.Time
+
+	// NewTicker returns ticker, which delivers ticks of the clock every passed interval
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks of Clock at intervals
+type Ticker interface {
+	// C returns channel of ticks
+	C() <-chan time.Time
+
+	// Stop turns ticker off
+	Stop()
+}
+
+// SystemClock is Clock based on system time
+type SystemClock struct {
+}
+
+// Now returns current system time
+func (c SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns ticker based on system time
+func (c SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{ticker: time.NewTicker(d)}
+}
+
+// systemTicker is Ticker based on system time
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns channel of ticks
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop turns ticker off
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
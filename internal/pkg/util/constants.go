@@ -2,9 +2,17 @@ package util
 
 // All project constants
 const (
-	DateFormatEu       string = "2006-01-02"
-	DateFormatRu              = "02-01-2006"
-	TimeFormat                = "15:04"
-	EndpointHistorical        = "historical"
-	EndpointLatest            = "latest"
+	DateFormatEu           string = "2006-01-02"
+	DateFormatRu                  = "02-01-2006"
+	TimeFormat                    = "15:04"
+	EndpointHistorical            = "historical"
+	EndpointLatest                = "latest"
+	ResolvePrevious               = "previous"
+	RateTypeMid                   = "mid"
+	RateTypeBid                   = "bid"
+	RateTypeAsk                   = "ask"
+	FormatNumber                  = "number"
+	FormatString                  = "string"
+	DefaultPrecision              = 6
+	DefaultProviderTimeout        = 30
 )
@@ -1,16 +1,9 @@
 package util
 
 import (
-	"math"
 	"time"
 )
 
-// ToFixed rounds passed num to target precision.
-func ToFixed(num float64, precision int) float64 {
-	output := math.Pow(10, float64(precision))
-	return float64(round(num*output)) / output
-}
-
 // GetToday returns today timeTime object.
 func GetToday(location *time.Location) time.Time {
 	var now time.Time
@@ -66,11 +59,6 @@ func GetDateRangeArr(startDate, endDate time.Time) []time.Time {
 	return dateRange
 }
 
-// round float to integer
-func round(num float64) int {
-	return int(num + math.Copysign(0.5, num))
-}
-
 // IsDateEquals returns true if dates are equal (no Timezone compared)
 func IsDateEquals(date1 time.Time, date2 time.Time) bool {
 	return date1.Year() == date2.Year() && date1.Month() == date2.Month() && date1.Day() == date2.Day()
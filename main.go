@@ -6,15 +6,24 @@
 // @license.name MIT
 // @license.url https://github.com/netandreus/go-forex-rates/blob/master/LICENSE
 // @BasePath /api/v1
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-Api-Key
 package main
 
 import (
 	"github.com/netandreus/go-forex-rates/api" // swagger docs.go
+	"github.com/netandreus/go-forex-rates/internal/pkg/anomaly"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/pipeline"
 	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider/composite"
+	"github.com/netandreus/go-forex-rates/internal/pkg/provider/consensus"
 	"github.com/netandreus/go-forex-rates/internal/pkg/provider/emirates"
 	"github.com/netandreus/go-forex-rates/internal/pkg/provider/fixer"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
 	"github.com/netandreus/go-forex-rates/pkg/server"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 	"strconv"
 )
@@ -26,6 +35,9 @@ var srv *server.Server
 func init() {
 	var err error
 
+	// Exact decimal rates are JSON numbers by default. Set once for the whole process, before any rates are marshalled
+	decimal.MarshalJSONWithoutQuotes = true
+
 	// Build srv (container, services etc)
 	srv, err = server.New()
 	if err != nil {
@@ -34,9 +46,18 @@ func init() {
 	}
 
 	// Add rates providers
-	srv.ContainerInvoke(func(registry *provider.Registry, db *gorm.DB, config *model.ApplicationConfig) {
-		registry.AddProvider(emirates.New(db, config))
-		registry.AddProvider(fixer.New(db, config))
+	srv.ContainerInvoke(func(
+		registry *provider.Registry,
+		db *gorm.DB,
+		config *model.ApplicationConfig,
+		availability *provider.Availability,
+		detector *anomaly.Detector,
+		rates *repository.RateRepository,
+		pipeline *pipeline.Pipeline) {
+		registry.AddProvider(emirates.New(db, config, availability, detector, rates))
+		registry.AddProvider(fixer.New(db, config, availability))
+		registry.AddProvider(composite.New(registry, pipeline, config, availability))
+		registry.AddProvider(consensus.New(registry, pipeline, config, availability))
 	})
 }
 
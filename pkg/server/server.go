@@ -7,21 +7,31 @@ import (
 	"github.com/fatih/color"
 	"github.com/gin-gonic/gin"
 	"github.com/go-co-op/gocron"
-	"github.com/gocolly/colly/v2"
+	"github.com/netandreus/go-forex-rates/internal/pkg/anomaly"
+	"github.com/netandreus/go-forex-rates/internal/pkg/auth"
+	"github.com/netandreus/go-forex-rates/internal/pkg/cache/snapshot"
 	"github.com/netandreus/go-forex-rates/internal/pkg/controller"
+	"github.com/netandreus/go-forex-rates/internal/pkg/correction"
+	"github.com/netandreus/go-forex-rates/internal/pkg/grpcapi"
+	"github.com/netandreus/go-forex-rates/internal/pkg/leader"
 	"github.com/netandreus/go-forex-rates/internal/pkg/logger"
 	"github.com/netandreus/go-forex-rates/internal/pkg/model"
+	"github.com/netandreus/go-forex-rates/internal/pkg/pipeline"
+	"github.com/netandreus/go-forex-rates/internal/pkg/preload"
 	"github.com/netandreus/go-forex-rates/internal/pkg/provider"
+	"github.com/netandreus/go-forex-rates/internal/pkg/repository"
 	"github.com/netandreus/go-forex-rates/internal/pkg/service"
+	"github.com/netandreus/go-forex-rates/internal/pkg/stream"
 	"github.com/netandreus/go-forex-rates/internal/pkg/util"
 	"go.uber.org/dig"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
-	"time"
 )
 
 // Server is server engine instance
@@ -70,16 +80,31 @@ func New() (*Server, error) {
 	return engine, nil
 }
 
-// Run starts cron listener, first time currency rates preload and REST HTTP server
+// Run starts cron listener, first time currency rates preload, gRPC server and REST HTTP server
 func (r *Server) Run() error {
 	var err error
+	// Load and save usage counters of API access keys
+	if err = r.container.Invoke(func(authenticator *auth.Authenticator) { authenticator.Start() }); err != nil {
+		return err
+	}
+
+	// Init gaps repair by cron
+	if err = r.container.Invoke(r.initGapRepair); err != nil {
+		return err
+	}
+
 	// Init auto-refresh currency rates by cron
 	if err = r.container.Invoke(r.initAutoRefreshRates); err != nil {
 		return err
 	}
 
-	// Run once when start
-	if err = r.container.Invoke(r.initFirstRefresh); err != nil {
+	// Run once when instance becomes preload leader
+	if err = r.container.Invoke(r.initLeaderElection); err != nil {
+		return err
+	}
+
+	// Run gRPC server alongside http server
+	if err = r.container.Invoke(r.runGrpc); err != nil {
 		return err
 	}
 
@@ -90,6 +115,24 @@ func (r *Server) Run() error {
 	return nil
 }
 
+// runGrpc starts gRPC server in background, if it is enabled
+func (r *Server) runGrpc(grpcServer *grpc.Server, config *model.ApplicationConfig) error {
+	if !config.Grpc.Enabled {
+		return nil
+	}
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(config.Grpc.Port))
+	if err != nil {
+		return errors.New("error starting gRPC server. " + err.Error())
+	}
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.LogError("gRPC server stopped. "+err.Error(), "GRPC")
+		}
+	}()
+	log.Print(color.GreenString("gRPC server listening on port " + strconv.Itoa(config.Grpc.Port)))
+	return nil
+}
+
 // GetListenPort returns REST HTTP server listen port
 func (r *Server) GetListenPort() int {
 	return r.config.Port
@@ -132,6 +175,15 @@ func (r *Server) buildControllers() error {
 	if err = r.container.Provide(controller.NewApiController); err != nil {
 		return err
 	}
+	if err = r.container.Provide(controller.NewAdminController); err != nil {
+		return err
+	}
+	if err = r.container.Provide(controller.NewCorrectionController); err != nil {
+		return err
+	}
+	if err = r.container.Provide(controller.NewStreamController); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -147,11 +199,6 @@ func (r *Server) buildServices() error {
 		return err
 	}
 
-	// Service *colly.Collector
-	if err = r.container.Provide(service.BuildColly); err != nil {
-		return err
-	}
-
 	// Service: *model.Config
 	if err = r.container.Provide(service.BuildConfig); err != nil {
 		return err
@@ -162,20 +209,145 @@ func (r *Server) buildServices() error {
 		return err
 	}
 
+	// Service: *gocache.Cache
+	if err = r.container.Provide(service.BuildL1Cache); err != nil {
+		return err
+	}
+
+	// Service: *MySQLStore
+	if err = r.container.Provide(service.BuildL2Cache); err != nil {
+		return err
+	}
+
 	// Service: *cache.Cache
 	if err = r.container.Provide(service.BuildCache); err != nil {
 		return err
 	}
 
+	// Service: *ApiKeyRepository
+	if err = r.container.Provide(repository.BuildApiKeyRepository); err != nil {
+		return err
+	}
+
+	// Service: *Authenticator
+	if err = r.container.Provide(auth.BuildAuthenticator); err != nil {
+		return err
+	}
+
 	// Service: *gin.Server
 	if err = r.container.Provide(service.BuildHttp); err != nil {
 		return err
 	}
 
+	// Service: *Pipeline
+	if err = r.container.Provide(pipeline.BuildPipeline); err != nil {
+		return err
+	}
+
+	// Service: *Hub
+	if err = r.container.Provide(stream.BuildHub); err != nil {
+		return err
+	}
+
+	// Service: *RatesServer
+	if err = r.container.Provide(grpcapi.BuildRatesServer); err != nil {
+		return err
+	}
+
+	// Service: *grpc.Server
+	if err = r.container.Provide(service.BuildGrpc); err != nil {
+		return err
+	}
+
 	// Service: *Registry
 	if err = r.container.Provide(provider.BuildRegistry); err != nil {
 		return err
 	}
+
+	// Service: util.Clock
+	if err = r.container.Provide(service.BuildClock); err != nil {
+		return err
+	}
+
+	// Service: *Availability
+	if err = r.container.Provide(provider.BuildAvailability); err != nil {
+		return err
+	}
+
+	// Service: *SnapshotRepository
+	if err = r.container.Provide(repository.BuildSnapshotRepository); err != nil {
+		return err
+	}
+
+	// Service: *AverageRepository
+	if err = r.container.Provide(repository.BuildAverageRepository); err != nil {
+		return err
+	}
+
+	// Service: *RateRepository
+	if err = r.container.Provide(repository.BuildRateRepository); err != nil {
+		return err
+	}
+
+	// Service: *QuarantineRepository
+	if err = r.container.Provide(repository.BuildQuarantineRepository); err != nil {
+		return err
+	}
+
+	// Service: *Detector
+	if err = r.container.Provide(anomaly.BuildDetector); err != nil {
+		return err
+	}
+
+	// Service: *GapScanner
+	if err = r.container.Provide(preload.BuildGapScanner); err != nil {
+		return err
+	}
+
+	// Service: *LeaseRepository
+	if err = r.container.Provide(repository.BuildLeaseRepository); err != nil {
+		return err
+	}
+
+	// Service: *Elector
+	if err = r.container.Provide(leader.BuildElector); err != nil {
+		return err
+	}
+
+	// Service: *JobRepository
+	if err = r.container.Provide(repository.BuildJobRepository); err != nil {
+		return err
+	}
+
+	// Service: *JobManager
+	if err = r.container.Provide(preload.BuildJobManager); err != nil {
+		return err
+	}
+
+	// Service: *Watcher
+	if err = r.container.Provide(preload.BuildWatcher); err != nil {
+		return err
+	}
+
+	// Service: *Refresher
+	if err = r.container.Provide(preload.BuildRefresher); err != nil {
+		return err
+	}
+
+	// Service: *AuditRepository
+	if err = r.container.Provide(repository.BuildAuditRepository); err != nil {
+		return err
+	}
+
+	// Service: *Corrector
+	if err = r.container.Provide(correction.BuildCorrector); err != nil {
+		return err
+	}
+
+	// Service: *LatestSnapshots
+	if err = r.container.Provide(snapshot.BuildLatestSnapshots); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -194,11 +366,15 @@ func (r *Server) getProvidersNeedToRatesPreload(config *model.ApplicationConfig)
 }
 
 // initFirstRefresh preloads historical currency rate for Emirates provider
-func (r *Server) initFirstRefresh(coll *colly.Collector, config *model.ApplicationConfig) error {
+func (r *Server) initFirstRefresh(jobManager *preload.JobManager, refresher *preload.Refresher, config *model.ApplicationConfig) error {
 	var (
-		endDate   time.Time
 		providers []provider.RatesProvider // providers need to refresh
 	)
+	// Resume jobs interrupted by restart
+	if err := jobManager.Resume(); err != nil {
+		return err
+	}
+
 	// Fetch providers need to
 	providers = r.getProvidersNeedToRatesPreload(config)
 
@@ -207,85 +383,134 @@ func (r *Server) initFirstRefresh(coll *colly.Collector, config *model.Applicati
 	}
 
 	for _, provider := range providers {
-		rateGenerationTime := provider.GetRateGenerationTime()
-		now := time.Now()
-		if now.Hour() >= rateGenerationTime.Hour() && now.Minute() > rateGenerationTime.Minute() && now.Second() >= rateGenerationTime.Second() {
-			endDate = util.GetToday(time.UTC)
-		} else {
-			endDate = util.GetYesterday(time.UTC)
+		refresher.Refresh(provider)
+	}
+	return nil
+}
+
+// initLeaderElection starts preload leader election. Leader makes first refresh and resumes unfinished jobs
+func (r *Server) initLeaderElection(
+	elector *leader.Elector,
+	jobManager *preload.JobManager,
+	refresher *preload.Refresher,
+	cron *gocron.Scheduler,
+	config *model.ApplicationConfig) error {
+	elector.OnElected(func() {
+		if err := r.initFirstRefresh(jobManager, refresher, config); err != nil {
+			logger.LogError(err.Error(), "JOB")
+		}
+	})
+
+	// Leader picks up jobs created by other instances and left by previous leader
+	if config.Leader.Enabled {
+		job, err := cron.Every(uint64(elector.GetTTL())).Seconds().Do(func() {
+			if err := jobManager.Resume(); err != nil {
+				logger.LogError(err.Error(), "JOB")
+			}
+		})
+		if err != nil {
+			return errors.New("invalid leader.ttl. " + err.Error())
 		}
-		r.refreshCurrencyRates(provider, coll, endDate)
+		job.SingletonMode()
 	}
+	elector.Start()
 	return nil
 }
 
-// initAutoRefreshRates initialize preload rates process now
-func (r *Server) initAutoRefreshRates(coll *colly.Collector, cron *gocron.Scheduler, config *model.ApplicationConfig) {
+// initAutoRefreshRates schedules preload of historical rates of providers by provider's cron expression
+// or daily at rates_generated_time. Each run calculates target date at run time
+func (r *Server) initAutoRefreshRates(
+	refresher *preload.Refresher,
+	availability *provider.Availability,
+	cron *gocron.Scheduler,
+	config *model.ApplicationConfig) error {
 	var (
-		today              = util.GetToday(time.UTC)
-		providers          []provider.RatesProvider // providers need to refresh
-		rateGenerationTime time.Time
+		providers []provider.RatesProvider // providers need to refresh
+		job       *gocron.Job
+		err       error
 	)
 	log.Print(color.GreenString("Application started"))
 
 	// Fetch providers need to
 	providers = r.getProvidersNeedToRatesPreload(config)
 
-	for _, provider := range providers {
-		rateGenerationTime = provider.GetRateGenerationTime()
+	for _, p := range providers {
+		provider := p
+		refresh := func() {
+			refresher.RunScheduled(provider)
+		}
 
 		// Run Cron
-		// 	job, _ := cron.Every(60).Seconds().Do(func() {
-		job, _ := cron.Every(1).Day().At(rateGenerationTime.Format(util.TimeFormat)).Do(func() {
-			log.Print("Cron event triggered")
-			// Options
-			//RefreshCurrencyRates(currencyRatesRepository, coll, time.Parse(constants.DateFormatEu, "2018-11-01"))
-			r.refreshCurrencyRates(provider, coll, today)
-		})
+		if schedule := provider.GetConfig().Schedule; schedule != "" {
+			job, err = cron.Cron(schedule).Do(refresh)
+		} else {
+			// rates_generated_time is in provider's location, cron is in UTC
+			publicationTime := availability.GetPublicationTime(provider).UTC()
+			job, err = cron.Every(1).Day().At(publicationTime.Format(util.TimeFormat)).Do(refresh)
+		}
+		if err != nil {
+			return errors.New("invalid schedule of provider " + provider.GetCode() + ". " + err.Error())
+		}
 		job.SingletonMode()
 	}
 	cron.StartAsync()
+	return nil
 }
 
-// refreshCurrencyRates preload rates from provider start date to given date for passed provider
-func (r *Server) refreshCurrencyRates(
-	provider provider.RatesProvider,
-	coll *colly.Collector,
-	endDate time.Time) {
-	// Form array of dates
-	startDate, _ := r.getRatesDateStart(provider)
-	dateRange := util.GetDateRangeArr(startDate, endDate)
-	dateRangeLength := len(dateRange)
-	if dateRangeLength == 0 {
-		log.Print("Currency rates database is filled")
-		return
-	}
-
-	// Register colly callbacks
-	coll.OnResponse(func(resp *colly.Response) {
-		dateStr := resp.Request.URL.Query().Get("date")
-		date, _ := time.Parse(util.DateFormatRu, dateStr)
-		provider.PreloadRates(date, true)
-		log.Print("Fetched for date " + dateStr)
+// initGapRepair schedules scan and repair of gaps in stored historical rates of preloaded providers
+func (r *Server) initGapRepair(
+	cron *gocron.Scheduler,
+	config *model.ApplicationConfig,
+	gapScanner *preload.GapScanner,
+	jobManager *preload.JobManager,
+	elector *leader.Elector) error {
+	if config.GapRepair.Schedule == "" {
+		return nil
+	}
+	providers := r.getProvidersNeedToRatesPreload(config)
+	job, err := cron.Cron(config.GapRepair.Schedule).Do(func() {
+		if !elector.IsLeader() {
+			return
+		}
+		for _, provider := range providers {
+			// Unfinished job of provider will continue preload
+			if unfinished, err := jobManager.HasUnfinished(provider.GetCode()); err != nil || unfinished {
+				continue
+			}
+			start, end, err := gapScanner.GetDefaultRange(provider)
+			if err != nil {
+				logger.LogError(err.Error(), "GAP")
+				continue
+			}
+			gaps, err := gapScanner.Scan(provider, start, end)
+			if err != nil {
+				logger.LogError(err.Error(), "GAP")
+				continue
+			}
+			if len(gaps) == 0 {
+				continue
+			}
+			logger.LogWarning(strconv.Itoa(len(gaps))+" gaps found in rates of provider "+provider.GetCode(), "GAP")
+			if _, err = jobManager.Start(provider, gapScanner.GetDates(gaps)); err != nil {
+				logger.LogError(err.Error(), "GAP")
+			}
+		}
 	})
-
-	// Add jobs
-	message := "Currency rates database needs filling"
-	message += " from " + startDate.Format(util.DateFormatEu)
-	message += " to " + endDate.Format(util.DateFormatEu)
-	log.Print(color.YellowString(message))
-	for i := 0; i < dateRangeLength; i++ {
-		date := dateRange[i]
-		dateString := date.Format(util.DateFormatRu)
-		url := "https://www.centralbank.ae/en/fx-rates-ajax?date=" + dateString + "&v=2"
-		coll.Visit(url)
+	if err != nil {
+		return errors.New("invalid gap_repair.schedule. " + err.Error())
 	}
+	job.SingletonMode()
+	return nil
 }
 
 // initOnClose creates a 'listener' on a new goroutine which will notify the
 // program if it receives an interrupt from the OS. We then handle this by calling
 // our clean up procedure and exiting the program.
-func (r *Server) initOnClose(cron *gocron.Scheduler) {
+func (r *Server) initOnClose(
+	cron *gocron.Scheduler,
+	elector *leader.Elector,
+	authenticator *auth.Authenticator,
+	grpcServer *grpc.Server) {
 	c := make(chan os.Signal)
 	signal.Notify(c, os.Interrupt, syscall.SIGKILL, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGABRT)
 	go func() {
@@ -293,31 +518,12 @@ func (r *Server) initOnClose(cron *gocron.Scheduler) {
 		log.Println(color.RedString("Receiving stop signal. Exiting..."))
 		// Stop cron
 		cron.Stop()
+		// Stop gRPC server, streaming subscriptions are closed
+		grpcServer.Stop()
+		// Release preload lease
+		elector.Stop()
+		// Save usage counters of API access keys
+		authenticator.Flush()
 		os.Exit(0)
 	}()
 }
-
-// getRatesDateStart returns given provider's historical rates start date
-func (r *Server) getRatesDateStart(provider provider.RatesProvider) (time.Time, error) {
-	var count int
-	var result time.Time
-	row := r.db.Table("currency_rate").
-		Select("count(id)").
-		Where("endpoint = ?", util.EndpointHistorical).
-		Where("provider = ?", provider.GetCode()).
-		Row()
-	row.Scan(&count)
-	if count == 0 {
-		// initial start date
-		providerStartDateStr := provider.GetConfig().HistoricalStartDate
-		return time.ParseInLocation(util.DateFormatEu, providerStartDateStr, provider.GetLocation())
-	}
-	row = r.db.Table("currency_rate").
-		Select("max(rate_date)").
-		Where("endpoint = ?", util.EndpointHistorical).
-		Where("provider = ?", provider.GetCode()).
-		Row()
-	row.Scan(&result)
-	result = result.AddDate(0, 0, 1)
-	return result, nil
-}